@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/collector"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// headerFlag collects repeated "Name: value" flags into an http.Header.
+type headerFlag http.Header
+
+func (f headerFlag) String() string {
+	var pairs []string
+	for name, values := range f {
+		for _, value := range values {
+			pairs = append(pairs, name+": "+value)
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (f headerFlag) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q, want \"Name: value\"", value)
+	}
+
+	http.Header(f).Add(strings.TrimSpace(name), strings.TrimSpace(val))
+	return nil
+}
+
+// nodeFlag collects repeated "name=url" or "url" flags. Nodes given by their
+// URL only are named after its scheme and host, as the rest of the URL may
+// hold credentials.
+type nodeFlag []nodeSpec
+
+type nodeSpec struct {
+	name string
+	url  string
+}
+
+func (f *nodeFlag) String() string {
+	var specs []string
+	for _, spec := range *f {
+		specs = append(specs, spec.name+"="+spec.url)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (f *nodeFlag) Set(value string) error {
+	name, rawURL, ok := strings.Cut(value, "=")
+	if !ok || strings.Contains(name, "/") {
+		name, rawURL = nodeName(value), value
+	}
+	if name == "" || rawURL == "" {
+		return fmt.Errorf("invalid node %q, want \"name=url\"", value)
+	}
+
+	*f = append(*f, nodeSpec{name: name, url: rawURL})
+	return nil
+}
+
+// nodeName returns the scheme and host of rawURL, leaving out user
+// information, path and query. Paths of IPC endpoints are kept.
+func nodeName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if u.Host == "" {
+		return u.Path
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// addressNameFlag collects repeated "address=name" flags.
+type addressNameFlag map[common.Address]string
+
+func (f addressNameFlag) String() string {
+	var pairs []string
+	for address, name := range f {
+		pairs = append(pairs, address.Hex()+"="+name)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f addressNameFlag) Set(value string) error {
+	address, name, ok := strings.Cut(value, "=")
+	if !ok || !common.IsHexAddress(address) || name == "" {
+		return fmt.Errorf("invalid address name %q, want \"address=name\"", value)
+	}
+
+	f[common.HexToAddress(address)] = name
+	return nil
+}
+
+// addressListFlag parses a comma separated list of addresses.
+type addressListFlag []common.Address
+
+func (f *addressListFlag) String() string {
+	var addresses []string
+	for _, address := range *f {
+		addresses = append(addresses, address.Hex())
+	}
+	return strings.Join(addresses, ",")
+}
+
+func (f *addressListFlag) Set(value string) error {
+	for _, address := range strings.Split(value, ",") {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid address %q", address)
+		}
+		*f = append(*f, common.HexToAddress(address))
+	}
+	return nil
+}
+
+// uint64ListFlag parses a comma separated list of numbers.
+type uint64ListFlag []uint64
+
+func (f *uint64ListFlag) String() string {
+	var numbers []string
+	for _, number := range *f {
+		numbers = append(numbers, strconv.FormatUint(number, 10))
+	}
+	return strings.Join(numbers, ",")
+}
+
+func (f *uint64ListFlag) Set(value string) error {
+	for _, n := range strings.Split(value, ",") {
+		number, err := strconv.ParseUint(n, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", n)
+		}
+		*f = append(*f, number)
+	}
+	return nil
+}
+
+// hashListFlag parses a comma separated list of hashes.
+type hashListFlag []common.Hash
+
+func (f *hashListFlag) String() string {
+	var hashes []string
+	for _, hash := range *f {
+		hashes = append(hashes, hash.Hex())
+	}
+	return strings.Join(hashes, ",")
+}
+
+func (f *hashListFlag) Set(value string) error {
+	for _, hash := range strings.Split(value, ",") {
+		b, err := hexutil.Decode(hash)
+		if err != nil || len(b) != common.HashLength {
+			return fmt.Errorf("invalid hash %q", hash)
+		}
+		*f = append(*f, common.BytesToHash(b))
+	}
+	return nil
+}
+
+// blobScheduleFlag parses a comma separated list of "timestamp:fraction"
+// blob forks.
+type blobScheduleFlag []collector.BlobFork
+
+func (f *blobScheduleFlag) String() string {
+	var forks []string
+	for _, fork := range *f {
+		forks = append(forks, strconv.FormatUint(fork.Timestamp, 10)+":"+strconv.FormatUint(fork.UpdateFraction, 10))
+	}
+	return strings.Join(forks, ",")
+}
+
+func (f *blobScheduleFlag) Set(value string) error {
+	for _, fork := range strings.Split(value, ",") {
+		timestamp, fraction, ok := strings.Cut(fork, ":")
+		t, err := strconv.ParseUint(timestamp, 10, 64)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid blob fork %q, want \"timestamp:fraction\"", fork)
+		}
+		n, err := strconv.ParseUint(fraction, 10, 64)
+		if err != nil || n == 0 {
+			return fmt.Errorf("invalid blob fork %q, want \"timestamp:fraction\"", fork)
+		}
+		*f = append(*f, collector.BlobFork{Timestamp: t, UpdateFraction: n})
+	}
+	return nil
+}
+
+// stringListFlag parses a comma separated list of strings. Items are trimmed,
+// and empty and repeated ones are dropped.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		duplicate := false
+		for _, existing := range *f {
+			duplicate = duplicate || existing == item
+		}
+		if !duplicate {
+			*f = append(*f, item)
+		}
+	}
+	return nil
+}
+
+// limitFlag parses a non-negative limit.
+type limitFlag int
+
+func (f *limitFlag) String() string {
+	return strconv.Itoa(int(*f))
+}
+
+func (f *limitFlag) Set(value string) error {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return fmt.Errorf("invalid limit %q, want a non-negative number", value)
+	}
+
+	*f = limitFlag(limit)
+	return nil
+}
+
+var labelNameRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// labelFlag collects repeated "name=value" flags into constant labels.
+type labelFlag prometheus.Labels
+
+func (f labelFlag) String() string {
+	var pairs []string
+	for name, value := range f {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f labelFlag) Set(value string) error {
+	name, value, ok := strings.Cut(value, "=")
+	if !ok || !labelNameRE.MatchString(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("invalid label %q, want \"name=value\"", name+"="+value)
+	}
+
+	f[name] = value
+	return nil
+}
+
+// attributeFlag collects repeated "name=value" flags into resource
+// attributes, whose names may contain dots.
+type attributeFlag map[string]string
+
+func (f attributeFlag) String() string {
+	var pairs []string
+	for name, value := range f {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f attributeFlag) Set(value string) error {
+	name, value, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid attribute %q, want \"name=value\"", name+"="+value)
+	}
+
+	f[name] = value
+	return nil
+}
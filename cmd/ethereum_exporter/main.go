@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/31z4/ethereum-prometheus-exporter/internal/beacon"
 	"github.com/31z4/ethereum-prometheus-exporter/internal/collector"
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/31z4/ethereum-prometheus-exporter/internal/push"
+	"github.com/31z4/ethereum-prometheus-exporter/internal/upstream"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -30,10 +41,110 @@ func main() {
 		os.Exit(2)
 	}
 
-	url := flag.String("url", "http://localhost:8545", "Ethereum JSON-RPC URL")
+	url := flag.String("url", "http://localhost:8545", "Ethereum JSON-RPC URL, or a comma separated list of \"name=url\" or \"url\" entries in order of preference")
 	addr := flag.String("addr", ":9368", "listen address")
 	ver := flag.Bool("v", false, "print version number and exit")
 
+	labels := make(labelFlag)
+	flag.Var(labels, "metrics.label", "constant label to add to every metric as \"name=value\" (repeatable)")
+	namespace := flag.String("metrics.namespace", "", "namespace to prefix every metric name with")
+
+	pushURL := flag.String("push.url", "", "Pushgateway or remote_write URL to periodically push metrics to, none if empty")
+	pushProtocol := flag.String("push.protocol", "pushgateway", "push protocol, either pushgateway or remote-write")
+	pushJob := flag.String("push.job", "ethereum_exporter", "job name to push metrics to a Pushgateway under")
+	pushGrouping := make(labelFlag)
+	flag.Var(pushGrouping, "push.grouping", "Pushgateway grouping key as \"name=value\" (repeatable)")
+	pushHeaders := make(http.Header)
+	flag.Var(headerFlag(pushHeaders), "push.header", "HTTP header to send with every push as \"Name: value\" (repeatable)")
+	pushInterval := flag.Duration("push.interval", 15*time.Second, "how often metrics are pushed")
+	pushTimeout := flag.Duration("push.timeout", 10*time.Second, "timeout of a push request")
+	pushRetries := flag.Int("push.retries", 3, "number of times a failed push is retried")
+	pushRetryBackoff := flag.Duration("push.retry-backoff", time.Second, "time to wait before the first retry of a failed push, doubled on every retry")
+	pushBuffer := flag.Int("push.buffer", 240, "maximum number of gathered batches to keep and resend while remote_write or OTLP export fails")
+
+	otlpEndpoint := flag.String("otlp.endpoint", "", "OTLP endpoint to periodically export metrics to, such as http://collector:4317 for gRPC or http://collector:4318/v1/metrics for HTTP, none if empty")
+	otlpProtocol := flag.String("otlp.protocol", "grpc", "OTLP protocol, either grpc or http")
+	otlpHeaders := make(http.Header)
+	flag.Var(headerFlag(otlpHeaders), "otlp.header", "HTTP header or gRPC metadata to send with every export as \"Name: value\" (repeatable)")
+	otlpResource := make(attributeFlag)
+	flag.Var(otlpResource, "otlp.resource", "resource attribute to export metrics with as \"name=value\" (repeatable)")
+	otlpInterval := flag.Duration("otlp.interval", 15*time.Second, "how often metrics are exported over OTLP")
+	otlpTimeout := flag.Duration("otlp.timeout", 10*time.Second, "timeout of an OTLP export")
+
+	healthInterval := flag.Duration("upstream.health-interval", 10*time.Second, "how often JSON-RPC URLs are health checked")
+	healthTimeout := flag.Duration("upstream.health-timeout", 5*time.Second, "timeout of a JSON-RPC URL health check")
+	followInterval := flag.Duration("follower.interval", 4*time.Second, "how often the chain is polled for new blocks")
+	followConfirmations := flag.Uint64("follower.confirmations", 0, "number of confirmations a block needs before it is followed, which keeps blocks replaced by shallower reorgs out of block metrics")
+	var blobSchedule blobScheduleFlag
+	flag.Var(&blobSchedule, "blobs.schedule", "comma separated list of \"timestamp:fraction\" blob base fee update fractions and the fork timestamps they apply from, those of eth_config if empty")
+	supply := flag.Bool("supply", false, "account for burned fees, priority fees and withdrawals of every block")
+	supplyStateFile := flag.String("supply.state-file", "", "file to persist supply accounting progress to")
+
+	recipientNames := make(addressNameFlag)
+	flag.Var(recipientNames, "fee-recipient.name", "name of a fee recipient as \"address=name\" (repeatable)")
+	recipientLimit := limitFlag(20)
+	flag.Var(&recipientLimit, "fee-recipient.limit", "maximum number of unnamed fee recipients to export per recipient metrics for, those of most blocks among the last 7200 ones")
+
+	clique := flag.Bool("clique", false, "collect Clique proof-of-authority metrics")
+	var cliqueSigners addressListFlag
+	flag.Var(&cliqueSigners, "clique.signers", "comma separated list of Clique signers expected to seal blocks")
+	cliqueInactiveBlocks := flag.Uint64("clique.inactive-blocks", 64, "number of blocks after which a Clique signer that has not sealed any is inactive")
+
+	gethDebugMetrics := flag.String("geth.debug-metrics", "", "regular expression of Geth debug_metrics names such as \"chain/inserts\" to export, none if empty")
+
+	traceMethod := flag.String("trace.method", "", "tracing method to probe, either trace_block or debug_traceBlockByNumber, none if empty")
+	traceTracer := flag.String("trace.tracer", "callTracer", "tracer of debug_traceBlockByNumber probes, the default one if empty")
+	traceBlock := flag.Uint64("trace.block", 0, "number of the block to trace, the most recent one if 0")
+	traceInterval := flag.Duration("trace.interval", time.Minute, "how often the tracing method is probed")
+	traceTimeout := flag.Duration("trace.timeout", 30*time.Second, "timeout of a tracing probe")
+
+	var archiveBlocks uint64ListFlag
+	flag.Var(&archiveBlocks, "archive.blocks", "comma separated list of historical block numbers to query the state at")
+	archiveSearch := flag.Bool("archive.search", false, "search for the oldest block whose state is served")
+	archiveAddress := flag.String("archive.address", "0x0000000000000000000000000000000000000000", "address whose balance and code are queried")
+	archiveInterval := flag.Duration("archive.interval", 10*time.Minute, "how often historical state is probed")
+	archiveTimeout := flag.Duration("archive.timeout", time.Minute, "timeout of a historical state probe")
+
+	txProbeKeyFile := flag.String("tx-probe.key-file", "", "file with the hex encoded private key of a funded account to send probe transactions from, none if empty")
+	var txProbeChainIDs uint64ListFlag
+	flag.Var(&txProbeChainIDs, "tx-probe.chain-ids", "comma separated list of chain IDs probe transactions may be sent on")
+	txProbeInterval := flag.Duration("tx-probe.interval", 5*time.Minute, "how often probe transactions are sent")
+	txProbePollInterval := flag.Duration("tx-probe.poll-interval", 2*time.Second, "how often the receipt of a probe transaction is polled")
+	txProbeTimeout := flag.Duration("tx-probe.timeout", 3*time.Minute, "time to wait for the inclusion of a probe transaction")
+
+	var watchTxs hashListFlag
+	flag.Var(&watchTxs, "watch.tx", "comma separated list of transaction hashes to watch")
+	watchAPIAddr := flag.String("watch.api-addr", "", "listen address, such as localhost:9369, to accept transactions to watch on with POST /watch/tx, disabled if empty")
+	watchConfirmations := flag.Uint64("watch.confirmations", 12, "number of confirmations after which a watched transaction expires")
+	watchPendingBlocks := flag.Uint64("watch.pending-blocks", 600, "number of blocks after which a watched transaction that is still pending expires")
+	watchDroppedBlocks := flag.Uint64("watch.dropped-blocks", 64, "number of blocks after which a watched transaction unknown to the node expires")
+	watchMaxTxs := limitFlag(1000)
+	flag.Var(&watchMaxTxs, "watch.max-txs", "maximum number of watched transactions")
+
+	var fleet nodeFlag
+	flag.Var(&fleet, "fleet.node", "JSON-RPC URL of a fleet node to compare heads with as \"name=url\" (repeatable)")
+	fleetTimeout := flag.Duration("fleet.timeout", 5*time.Second, "timeout of fleet node queries")
+
+	referenceURL := flag.String("reference.url", "", "JSON-RPC URL of a reference node to measure head lag against")
+	referenceInterval := flag.Duration("reference.interval", time.Minute, "how often the reference node is queried")
+	referenceTimeout := flag.Duration("reference.timeout", 5*time.Second, "timeout of reference node queries")
+
+	beaconURL := flag.String("beacon.url", "", "Beacon node REST API URL of the consensus client paired with the execution client")
+	beaconTimeout := flag.Duration("beacon.timeout", 10*time.Second, "timeout of Beacon node REST API requests")
+	var beaconValidators stringListFlag
+	flag.Var(&beaconValidators, "beacon.validators", "comma separated list of validator indices or public keys to monitor")
+	beaconValidatorSeries := limitFlag(100)
+	flag.Var(&beaconValidatorSeries, "beacon.validator-series-limit", "maximum number of validators to export per validator metrics for")
+
+	rpcOpts := upstream.Options{Headers: make(http.Header)}
+	flag.Var(headerFlag(rpcOpts.Headers), "rpc.header", "HTTP header to send to the JSON-RPC endpoint as \"Name: value\" (repeatable)")
+	flag.StringVar(&rpcOpts.BearerTokenFile, "rpc.bearer-token-file", "", "file with a bearer token for the JSON-RPC endpoint")
+	flag.StringVar(&rpcOpts.JWTSecretFile, "rpc.jwt-secret-file", "", "file with a hex encoded secret to sign Engine API style JWTs")
+	flag.StringVar(&rpcOpts.TLSCertFile, "rpc.tls-cert", "", "client certificate file for mutual TLS")
+	flag.StringVar(&rpcOpts.TLSKeyFile, "rpc.tls-key", "", "client private key file for mutual TLS")
+	flag.StringVar(&rpcOpts.TLSCAFile, "rpc.tls-ca", "", "CA certificates file to verify the JSON-RPC endpoint")
+	flag.BoolVar(&rpcOpts.TLSInsecureSkipVerify, "rpc.tls-insecure-skip-verify", false, "do not verify the JSON-RPC endpoint certificate")
+
 	flag.Parse()
 	if len(flag.Args()) > 0 {
 		flag.Usage()
@@ -44,30 +155,310 @@ func main() {
 		os.Exit(0)
 	}
 
-	rpc, err := rpc.Dial(*url)
+	var endpoints nodeFlag
+	for _, value := range strings.Split(*url, ",") {
+		if err := endpoints.Set(value); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var targets []upstream.Target
+	for _, spec := range endpoints {
+		targets = append(targets, upstream.Target{Name: spec.name, URL: spec.url})
+	}
+	pool, err := upstream.NewPool(targets, &rpcOpts, *healthTimeout)
 	if err != nil {
 		log.Fatal(err)
 	}
+	go pool.Run(context.Background(), *healthInterval)
+
+	var debugMetrics *regexp.Regexp
+	if *gethDebugMetrics != "" {
+		debugMetrics, err = regexp.Compile(*gethDebugMetrics)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var reference *collector.ReferenceHead
+	if *referenceURL != "" {
+		// Credentials of the monitored node are not sent to the reference.
+		rpc, err := upstream.Dial(*referenceURL, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reference = collector.NewReferenceHead(rpc, *referenceInterval, *referenceTimeout)
+	}
 
 	registry := prometheus.NewPedanticRegistry()
-	registry.MustRegister(
-		collector.NewNetPeerCount(rpc),
-		collector.NewEthBlockNumber(rpc),
-		collector.NewEthBlockTimestamp(rpc),
-		collector.NewEthGasPrice(rpc),
-		collector.NewEthEarliestBlockTransactions(rpc),
-		collector.NewEthLatestBlockTransactions(rpc),
-		collector.NewEthPendingBlockTransactions(rpc),
-		collector.NewEthHashrate(rpc),
-		collector.NewEthSyncing(rpc),
-		collector.NewParityNetPeers(rpc),
-	)
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels(labels), registry)
+	if *namespace != "" {
+		if !labelNameRE.MatchString(*namespace) {
+			log.Fatalf("invalid namespace %q", *namespace)
+		}
+		registerer = prometheus.WrapRegistererWithPrefix(*namespace+"_", registerer)
+	}
+	// Registration fails when a constant label clashes with a label of a
+	// described metric.
+	register := func(collectors ...prometheus.Collector) {
+		for _, c := range collectors {
+			if err := registerer.Register(c); err != nil {
+				log.Fatalf("failed to register metrics: %v", err)
+			}
+		}
+	}
+
+	upstreams := upstream.NewCollector(pool, func(rpc *rpc.Client) (chain, node []prometheus.Collector) {
+		chain = []prometheus.Collector{
+			collector.NewEthBlockNumber(rpc),
+			collector.NewEthBlockTimestamp(rpc),
+			collector.NewEthGasPrice(rpc),
+			collector.NewEthBlobBaseFee(rpc),
+			collector.NewEthEarliestBlockTransactions(rpc),
+			collector.NewEthLatestBlockTransactions(rpc),
+			collector.NewEthLatestBlockContents(rpc),
+		}
+		node = []prometheus.Collector{
+			collector.NewNetPeerCount(rpc),
+			collector.NewEthPendingBlockTransactions(rpc),
+			collector.NewPoW(rpc),
+			collector.NewEthSyncing(rpc),
+			collector.NewParityNetPeers(rpc),
+		}
+		if reference != nil {
+			node = append(node, collector.NewEthHeadLag(rpc, reference))
+		}
+		if debugMetrics != nil {
+			node = append(node, collector.NewGethDebugMetrics(rpc, debugMetrics))
+		}
+		if *clique {
+			node = append(node,
+				collector.NewCliqueSigners(rpc),
+				collector.NewCliqueStatus(rpc),
+				collector.NewCliqueProposals(rpc),
+			)
+		}
+
+		// Client specific collectors are added once the client is detected.
+		node = append(node, collector.NewClientCollector(rpc, func(client collector.Client) []prometheus.Collector {
+			switch client {
+			case collector.ClientBesu:
+				return []prometheus.Collector{
+					collector.NewBFTValidators(rpc, "qbft_getValidatorsByBlockNumber", "ibft_getValidatorsByBlockNumber"),
+					collector.NewBFTSignerMetrics(rpc, "qbft_getSignerMetrics", "ibft_getSignerMetrics"),
+				}
+			case collector.ClientQuorum:
+				return []prometheus.Collector{
+					collector.NewBFTValidators(rpc, "istanbul_getValidators"),
+					collector.NewIstanbulStatus(rpc),
+				}
+			case collector.ClientOpenEthereum:
+				return []prometheus.Collector{
+					collector.NewParityPendingTransactionsStats(rpc),
+					collector.NewParityNodeKind(rpc),
+					collector.NewParityMode(rpc),
+					collector.NewParityChainStatus(rpc),
+					collector.NewParityAllTransactions(rpc),
+				}
+			case collector.ClientNethermind:
+				return []prometheus.Collector{
+					collector.NewNethermindSyncStage(rpc),
+					collector.NewNethermindHealth(rpc),
+					collector.NewNethermindLocalEnode(rpc),
+				}
+			case collector.ClientErigon:
+				return []prometheus.Collector{
+					collector.NewErigonStages(rpc),
+					collector.NewErigonBlockNumber(rpc),
+					collector.NewErigonForks(rpc),
+				}
+			}
+			return nil
+		}))
+		return chain, node
+	})
+	register(upstreams, upstreams.Unchecked())
+
+	active := func() *rpc.Client { return pool.Active().Client }
+	blocks := follower.New(active, *followInterval, *followConfirmations)
+	register(blocks)
+
+	blobs := collector.NewEthBlobs(active, blobSchedule)
+	blocks.Subscribe(blobs)
+	register(blobs)
+
+	fees := collector.NewEthPriorityFees()
+	blocks.Subscribe(fees)
+	register(fees)
+
+	recipients := collector.NewEthFeeRecipients(recipientNames, int(recipientLimit))
+	blocks.Subscribe(recipients)
+	register(recipients)
+
+	if *clique && len(cliqueSigners) > 0 {
+		activity := collector.NewCliqueSignerActivity(active, cliqueSigners, *cliqueInactiveBlocks)
+		blocks.Subscribe(activity)
+		register(activity)
+	}
+
+	if *supply {
+		supply, err := collector.NewEthSupply(active, *supplyStateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Supply accounting resumes from its own progress with a follower of
+		// its own, so that other handlers do not replay history. Reorgs are
+		// counted by the shared follower only.
+		supplyBlocks := follower.New(active, *followInterval, *followConfirmations)
+		if next, ok := supply.Next(); ok {
+			supplyBlocks.StartAt(next)
+		}
+		supplyBlocks.Subscribe(supply)
+		register(supply)
+		go supplyBlocks.Run(context.Background())
+	}
+
+	if len(watchTxs) > 0 || *watchAPIAddr != "" {
+		watcher := collector.NewTxWatcher(active, *watchConfirmations, *watchPendingBlocks, *watchDroppedBlocks, int(watchMaxTxs))
+		for _, hash := range watchTxs {
+			if err := watcher.Watch(hash); err != nil {
+				log.Fatal(err)
+			}
+		}
+		blocks.Subscribe(watcher)
+		register(watcher)
+
+		if *watchAPIAddr != "" {
+			// Registering transactions changes the state of the exporter, so
+			// the API is never served on the public scrape port.
+			mux := http.NewServeMux()
+			mux.Handle("/watch/tx", watcher)
+			go func() {
+				log.Fatal(http.ListenAndServe(*watchAPIAddr, mux))
+			}()
+		}
+	}
+
+	go blocks.Run(context.Background())
+
+	if *traceMethod != "" {
+		probe, err := collector.NewTraceProbe(active, *traceMethod, *traceTracer, *traceBlock, *traceTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go probe.Run(context.Background(), *traceInterval)
+		register(probe)
+	}
+
+	if len(archiveBlocks) > 0 || *archiveSearch {
+		if !common.IsHexAddress(*archiveAddress) {
+			log.Fatalf("invalid address %q", *archiveAddress)
+		}
+		address := common.HexToAddress(*archiveAddress)
+		probe := collector.NewArchiveProbe(active, address, archiveBlocks, *archiveSearch, *archiveTimeout)
+		go probe.Run(context.Background(), *archiveInterval)
+		register(probe)
+	}
+
+	if *txProbeKeyFile != "" {
+		key, err := crypto.LoadECDSA(*txProbeKeyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		probe, err := collector.NewTxProbe(active, key, txProbeChainIDs, *txProbePollInterval, *txProbeTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go probe.Run(context.Background(), *txProbeInterval)
+		register(probe)
+	}
+
+	if len(fleet) > 0 {
+		var nodes []collector.Node
+		for _, spec := range fleet {
+			rpc, err := upstream.Dial(spec.url, &rpcOpts)
+			if err != nil {
+				log.Fatal(err)
+			}
+			nodes = append(nodes, collector.Node{Name: spec.name, RPC: rpc})
+		}
+		register(collector.NewEthNodes(nodes, *fleetTimeout))
+	}
+
+	if *beaconURL != "" {
+		client := beacon.NewClient(*beaconURL, &http.Client{Timeout: *beaconTimeout})
+		register(
+			beacon.NewNodeSyncing(client),
+			beacon.NewNodePeerCount(client),
+			beacon.NewBeaconHead(client),
+			beacon.NewBeaconFinalityCheckpoints(client),
+			beacon.NewNodeVersion(client),
+			// The consensus client is compared with the active execution client.
+			collector.NewEnginePairing(active, client),
+		)
+		if len(beaconValidators) > 0 {
+			register(beacon.NewBeaconValidators(client, beaconValidators, int(beaconValidatorSeries)))
+		}
+	}
 
 	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{
 		ErrorLog:      log.New(os.Stderr, log.Prefix(), log.Flags()),
 		ErrorHandling: promhttp.ContinueOnError,
 	})
 
+	if *pushURL != "" {
+		client := &http.Client{Timeout: *pushTimeout}
+
+		var target push.Target
+		switch *pushProtocol {
+		case "pushgateway":
+			target = push.NewPushgateway(*pushURL, *pushJob, pushGrouping, client, pushHeaders)
+		case "remote-write":
+			target = push.NewRemoteWrite(*pushURL, client, pushHeaders)
+		default:
+			log.Fatalf("unsupported push protocol %q", *pushProtocol)
+		}
+
+		pusher := push.NewPusher(registry, target, *pushRetries, *pushRetryBackoff, *pushBuffer)
+		go pusher.Run(context.Background(), *pushInterval)
+	}
+
+	if *otlpEndpoint != "" {
+		if *otlpProtocol != "grpc" && *otlpProtocol != "http" {
+			log.Fatalf("unsupported OTLP protocol %q", *otlpProtocol)
+		}
+
+		resource := func(ctx context.Context) (map[string]string, error) {
+			attributes := map[string]string{
+				"service.name":    "ethereum_exporter",
+				"service.version": version,
+			}
+			for name, value := range otlpResource {
+				attributes[name] = value
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, *otlpTimeout)
+			defer cancel()
+
+			// The chain and client are those of the active endpoint.
+			var chainID hexutil.Big
+			if err := active().CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+				return attributes, err
+			}
+			var clientVersion string
+			if err := active().CallContext(ctx, &clientVersion, "web3_clientVersion"); err != nil {
+				return attributes, err
+			}
+			attributes["ethereum.chain_id"] = chainID.ToInt().String()
+			attributes["ethereum.client_version"] = clientVersion
+
+			return attributes, nil
+		}
+
+		target := push.NewOTLP(*otlpEndpoint, *otlpProtocol == "grpc", *otlpTimeout, otlpHeaders, resource)
+		pusher := push.NewPusher(registry, target, *pushRetries, *pushRetryBackoff, *pushBuffer)
+		go pusher.Run(context.Background(), *otlpInterval)
+	}
+
 	http.Handle("/metrics", handler)
 	log.Fatal(http.ListenAndServe(*addr, nil))
 }
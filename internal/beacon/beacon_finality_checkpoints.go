@@ -0,0 +1,72 @@
+package beacon
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type BeaconFinalityCheckpoints struct {
+	client                *Client
+	finalizedDesc         *prometheus.Desc
+	currentJustifiedDesc  *prometheus.Desc
+	previousJustifiedDesc *prometheus.Desc
+}
+
+type checkpoint struct {
+	Epoch uint64 `json:"epoch,string"`
+	Root  string
+}
+
+type finalityCheckpointsResult struct {
+	PreviousJustified checkpoint `json:"previous_justified"`
+	CurrentJustified  checkpoint `json:"current_justified"`
+	Finalized         checkpoint
+}
+
+func NewBeaconFinalityCheckpoints(client *Client) *BeaconFinalityCheckpoints {
+	return &BeaconFinalityCheckpoints{
+		client: client,
+		finalizedDesc: prometheus.NewDesc(
+			"beacon_finalized_epoch",
+			"epoch of the finalized checkpoint of the head state",
+			nil,
+			nil,
+		),
+		currentJustifiedDesc: prometheus.NewDesc(
+			"beacon_current_justified_epoch",
+			"epoch of the current justified checkpoint of the head state",
+			nil,
+			nil,
+		),
+		previousJustifiedDesc: prometheus.NewDesc(
+			"beacon_previous_justified_epoch",
+			"epoch of the previous justified checkpoint of the head state",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *BeaconFinalityCheckpoints) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.finalizedDesc
+	ch <- collector.currentJustifiedDesc
+	ch <- collector.previousJustifiedDesc
+}
+
+func (collector *BeaconFinalityCheckpoints) Collect(ch chan<- prometheus.Metric) {
+	var result finalityCheckpointsResult
+	if err := collector.client.Get(context.Background(), "/eth/v1/beacon/states/head/finality_checkpoints", &result); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.finalizedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.currentJustifiedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.previousJustifiedDesc, err)
+		return
+	}
+
+	value := float64(result.Finalized.Epoch)
+	ch <- prometheus.MustNewConstMetric(collector.finalizedDesc, prometheus.GaugeValue, value)
+	value = float64(result.CurrentJustified.Epoch)
+	ch <- prometheus.MustNewConstMetric(collector.currentJustifiedDesc, prometheus.GaugeValue, value)
+	value = float64(result.PreviousJustified.Epoch)
+	ch <- prometheus.MustNewConstMetric(collector.previousJustifiedDesc, prometheus.GaugeValue, value)
+}
@@ -0,0 +1,58 @@
+package beacon
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestBeaconFinalityCheckpointsCollectError(t *testing.T) {
+	collector := NewBeaconFinalityCheckpoints(NewClient("http://localhost", http.DefaultClient))
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestBeaconFinalityCheckpointsCollect(t *testing.T) {
+	server := newServer(t, "/eth/v1/beacon/states/head/finality_checkpoints", `{"data": {"previous_justified": {"epoch": "238123", "root": "0x0"}, "current_justified": {"epoch": "238124", "root": "0x0"}, "finalized": {"epoch": "238122", "root": "0x0"}}}`)
+	defer server.Close()
+
+	collector := NewBeaconFinalityCheckpoints(NewClient(server.URL, http.DefaultClient))
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	for _, want := range []float64{238122, 238124, 238123} {
+		var metric dto.Metric
+		if err := (<-ch).Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := *metric.Gauge.Value; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
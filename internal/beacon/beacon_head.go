@@ -0,0 +1,47 @@
+package beacon
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type BeaconHead struct {
+	client *Client
+	desc   *prometheus.Desc
+}
+
+type headerResult struct {
+	Header struct {
+		Message struct {
+			Slot uint64 `json:"slot,string"`
+		}
+	}
+}
+
+func NewBeaconHead(client *Client) *BeaconHead {
+	return &BeaconHead{
+		client: client,
+		desc: prometheus.NewDesc(
+			"beacon_head_slot",
+			"slot of the head block of the beacon node",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *BeaconHead) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *BeaconHead) Collect(ch chan<- prometheus.Metric) {
+	var result headerResult
+	if err := collector.client.Get(context.Background(), "/eth/v1/beacon/headers/head", &result); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	value := float64(result.Header.Message.Slot)
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
@@ -0,0 +1,58 @@
+package beacon
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestBeaconHeadCollectError(t *testing.T) {
+	collector := NewBeaconHead(NewClient("http://localhost", http.DefaultClient))
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestBeaconHeadCollect(t *testing.T) {
+	server := newServer(t, "/eth/v1/beacon/headers/head", `{"data": {"root": "0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2", "canonical": true, "header": {"message": {"slot": "7620001", "proposer_index": "1", "parent_root": "0x0", "state_root": "0x0", "body_root": "0x0"}, "signature": "0x0"}}}`)
+	defer server.Close()
+
+	collector := NewBeaconHead(NewClient(server.URL, http.DefaultClient))
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := *metric.Gauge.Value; got != 7620001 {
+			t.Fatalf("got %v, want 7620001", got)
+		}
+	}
+}
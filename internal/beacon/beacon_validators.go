@@ -0,0 +1,310 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// validatorBatchSize is the number of validators requested at once, which
+// keeps request URLs well below common length limits.
+const validatorBatchSize = 64
+
+// validatorStatuses are the validator statuses defined by the Beacon API.
+var validatorStatuses = []string{
+	"pending_initialized",
+	"pending_queued",
+	"active_ongoing",
+	"active_exiting",
+	"active_slashed",
+	"exited_unslashed",
+	"exited_slashed",
+	"withdrawal_possible",
+	"withdrawal_done",
+}
+
+var errInvalidSpec = errors.New("beacon spec has no SLOTS_PER_EPOCH")
+
+// BeaconValidators monitors a configured list of validators. Per validator
+// metrics are only exported for the first maxSeries validators by index,
+// aggregate counts are exported for all of them.
+type BeaconValidators struct {
+	client               *Client
+	ids                  []string
+	maxSeries            int
+	countDesc            *prometheus.Desc
+	balanceDesc          *prometheus.Desc
+	effectiveBalanceDesc *prometheus.Desc
+	statusDesc           *prometheus.Desc
+	slashedDesc          *prometheus.Desc
+	liveDesc             *prometheus.Desc
+	missedProposalsDesc  *prometheus.Desc
+
+	mu            sync.Mutex
+	slotsPerEpoch uint64
+}
+
+type validatorResult struct {
+	Index     uint64 `json:"index,string"`
+	Balance   uint64 `json:"balance,string"`
+	Status    string
+	Validator struct {
+		Pubkey           string
+		EffectiveBalance uint64 `json:"effective_balance,string"`
+		Slashed          bool
+	}
+}
+
+type livenessResult struct {
+	Index  uint64 `json:"index,string"`
+	IsLive bool   `json:"is_live"`
+}
+
+type proposerDutyResult struct {
+	ValidatorIndex uint64 `json:"validator_index,string"`
+	Slot           uint64 `json:"slot,string"`
+}
+
+type specResult struct {
+	SlotsPerEpoch uint64 `json:"SLOTS_PER_EPOCH,string"`
+}
+
+func NewBeaconValidators(client *Client, ids []string, maxSeries int) *BeaconValidators {
+	labels := []string{"index", "pubkey"}
+
+	return &BeaconValidators{
+		client:    client,
+		ids:       ids,
+		maxSeries: maxSeries,
+		countDesc: prometheus.NewDesc(
+			"beacon_validators",
+			"number of monitored validators by status",
+			[]string{"status"},
+			nil,
+		),
+		balanceDesc: prometheus.NewDesc(
+			"beacon_validator_balance_gwei",
+			"balance of the validator in gwei",
+			labels,
+			nil,
+		),
+		effectiveBalanceDesc: prometheus.NewDesc(
+			"beacon_validator_effective_balance_gwei",
+			"effective balance of the validator in gwei",
+			labels,
+			nil,
+		),
+		statusDesc: prometheus.NewDesc(
+			"beacon_validator_status",
+			"status of the validator",
+			append(labels, "status"),
+			nil,
+		),
+		slashedDesc: prometheus.NewDesc(
+			"beacon_validator_slashed",
+			"whether the validator has been slashed",
+			labels,
+			nil,
+		),
+		liveDesc: prometheus.NewDesc(
+			"beacon_validator_live",
+			"whether the validator was live in the previous epoch",
+			labels,
+			nil,
+		),
+		missedProposalsDesc: prometheus.NewDesc(
+			"beacon_validator_missed_proposals",
+			"number of block proposals the validator missed in the previous epoch",
+			labels,
+			nil,
+		),
+	}
+}
+
+func (collector *BeaconValidators) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.countDesc
+	ch <- collector.balanceDesc
+	ch <- collector.effectiveBalanceDesc
+	ch <- collector.statusDesc
+	ch <- collector.slashedDesc
+	ch <- collector.liveDesc
+	ch <- collector.missedProposalsDesc
+}
+
+func (collector *BeaconValidators) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	validators, err := collector.getValidators(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.countDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.balanceDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.effectiveBalanceDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.statusDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.slashedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.liveDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.missedProposalsDesc, err)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, validator := range validators {
+		counts[validator.Status]++
+	}
+	for _, status := range validatorStatuses {
+		ch <- prometheus.MustNewConstMetric(collector.countDesc, prometheus.GaugeValue, float64(counts[status]), status)
+	}
+
+	if len(validators) > collector.maxSeries {
+		validators = validators[:collector.maxSeries]
+	}
+	if len(validators) == 0 {
+		return
+	}
+
+	labels := make(map[uint64][]string, len(validators))
+	for _, validator := range validators {
+		l := []string{strconv.FormatUint(validator.Index, 10), validator.Validator.Pubkey}
+		labels[validator.Index] = l
+
+		value := float64(validator.Balance)
+		ch <- prometheus.MustNewConstMetric(collector.balanceDesc, prometheus.GaugeValue, value, l...)
+		value = float64(validator.Validator.EffectiveBalance)
+		ch <- prometheus.MustNewConstMetric(collector.effectiveBalanceDesc, prometheus.GaugeValue, value, l...)
+		ch <- prometheus.MustNewConstMetric(collector.statusDesc, prometheus.GaugeValue, 1, append(l, validator.Status)...)
+		ch <- prometheus.MustNewConstMetric(collector.slashedDesc, prometheus.GaugeValue, boolToFloat(validator.Validator.Slashed), l...)
+	}
+
+	epoch, err := collector.getPreviousEpoch(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.liveDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.missedProposalsDesc, err)
+		return
+	}
+
+	collector.collectLiveness(ctx, ch, epoch, labels)
+	collector.collectMissedProposals(ctx, ch, epoch, labels)
+}
+
+// getValidators returns the configured validators sorted by index.
+func (collector *BeaconValidators) getValidators(ctx context.Context) ([]validatorResult, error) {
+	var validators []validatorResult
+
+	for start := 0; start < len(collector.ids); start += validatorBatchSize {
+		end := start + validatorBatchSize
+		if end > len(collector.ids) {
+			end = len(collector.ids)
+		}
+
+		var batch []validatorResult
+		path := "/eth/v1/beacon/states/head/validators?id=" + url.QueryEscape(strings.Join(collector.ids[start:end], ","))
+		if err := collector.client.Get(ctx, path, &batch); err != nil {
+			return nil, err
+		}
+		validators = append(validators, batch...)
+	}
+
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i].Index < validators[j].Index
+	})
+
+	return validators, nil
+}
+
+func (collector *BeaconValidators) getPreviousEpoch(ctx context.Context) (uint64, error) {
+	slotsPerEpoch, err := collector.getSlotsPerEpoch(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var head headerResult
+	if err := collector.client.Get(ctx, "/eth/v1/beacon/headers/head", &head); err != nil {
+		return 0, err
+	}
+
+	epoch := head.Header.Message.Slot / slotsPerEpoch
+	if epoch > 0 {
+		epoch--
+	}
+	return epoch, nil
+}
+
+// getSlotsPerEpoch returns the epoch length from the chain spec. It never
+// changes, so it is only requested once.
+func (collector *BeaconValidators) getSlotsPerEpoch(ctx context.Context) (uint64, error) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.slotsPerEpoch > 0 {
+		return collector.slotsPerEpoch, nil
+	}
+
+	var spec specResult
+	if err := collector.client.Get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+		return 0, err
+	}
+	if spec.SlotsPerEpoch == 0 {
+		return 0, errInvalidSpec
+	}
+
+	collector.slotsPerEpoch = spec.SlotsPerEpoch
+	return collector.slotsPerEpoch, nil
+}
+
+func (collector *BeaconValidators) collectLiveness(ctx context.Context, ch chan<- prometheus.Metric, epoch uint64, labels map[uint64][]string) {
+	var indices []string
+	for index := range labels {
+		indices = append(indices, strconv.FormatUint(index, 10))
+	}
+
+	var liveness []livenessResult
+	path := fmt.Sprintf("/eth/v1/validator/liveness/%d", epoch)
+	if err := collector.client.Post(ctx, path, indices, &liveness); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.liveDesc, err)
+		return
+	}
+
+	for _, result := range liveness {
+		if l, ok := labels[result.Index]; ok {
+			ch <- prometheus.MustNewConstMetric(collector.liveDesc, prometheus.GaugeValue, boolToFloat(result.IsLive), l...)
+		}
+	}
+}
+
+func (collector *BeaconValidators) collectMissedProposals(ctx context.Context, ch chan<- prometheus.Metric, epoch uint64, labels map[uint64][]string) {
+	var duties []proposerDutyResult
+	path := fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch)
+	if err := collector.client.Get(ctx, path, &duties); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.missedProposalsDesc, err)
+		return
+	}
+
+	missed := make(map[uint64]int)
+	for _, duty := range duties {
+		if _, ok := labels[duty.ValidatorIndex]; !ok {
+			continue
+		}
+
+		// A slot without a block means the proposal was missed.
+		var header headerResult
+		err := collector.client.Get(ctx, fmt.Sprintf("/eth/v1/beacon/headers/%d", duty.Slot), &header)
+
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.Code == 404 {
+			missed[duty.ValidatorIndex]++
+		} else if err != nil {
+			ch <- prometheus.NewInvalidMetric(collector.missedProposalsDesc, err)
+			return
+		}
+	}
+
+	for index, l := range labels {
+		ch <- prometheus.MustNewConstMetric(collector.missedProposalsDesc, prometheus.GaugeValue, float64(missed[index]), l...)
+	}
+}
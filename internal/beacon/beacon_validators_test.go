@@ -0,0 +1,175 @@
+package beacon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func newValidatorsServer(t *testing.T, requests *int) *httptest.Server {
+	write := func(w http.ResponseWriter, response string) {
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/beacon/states/head/validators", func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		var validators []string
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			status, slashed := "active_ongoing", false
+			if id == "2" {
+				status, slashed = "active_slashed", true
+			}
+			validators = append(validators, fmt.Sprintf(`{"index": "%s", "balance": "32000123456", "status": "%s", "validator": {"pubkey": "0x%s", "effective_balance": "32000000000", "slashed": %t}}`, id, status, id, slashed))
+		}
+		write(w, `{"data": [`+strings.Join(validators, ",")+`]}`)
+	})
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, r *http.Request) {
+		write(w, `{"data": {"SLOTS_PER_EPOCH": "32"}}`)
+	})
+	mux.HandleFunc("/eth/v1/beacon/headers/head", func(w http.ResponseWriter, r *http.Request) {
+		write(w, `{"data": {"header": {"message": {"slot": "100"}}}}`)
+	})
+	mux.HandleFunc("/eth/v1/validator/liveness/2", func(w http.ResponseWriter, r *http.Request) {
+		var indices []string
+		if err := json.NewDecoder(r.Body).Decode(&indices); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		var liveness []string
+		for _, index := range indices {
+			liveness = append(liveness, fmt.Sprintf(`{"index": "%s", "is_live": %t}`, index, index != "1"))
+		}
+		write(w, `{"data": [`+strings.Join(liveness, ",")+`]}`)
+	})
+	mux.HandleFunc("/eth/v1/validator/duties/proposer/2", func(w http.ResponseWriter, r *http.Request) {
+		write(w, `{"data": [{"validator_index": "1", "slot": "64"}, {"validator_index": "0", "slot": "65"}, {"validator_index": "999", "slot": "66"}]}`)
+	})
+	mux.HandleFunc("/eth/v1/beacon/headers/64", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		write(w, `{"code": 404, "message": "not found"}`)
+	})
+	mux.HandleFunc("/eth/v1/beacon/headers/65", func(w http.ResponseWriter, r *http.Request) {
+		write(w, `{"data": {"header": {"message": {"slot": "65"}}}}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func collectBeaconValidators(t *testing.T, collector *BeaconValidators) map[string]float64 {
+	ch := make(chan prometheus.Metric, 1024)
+
+	collector.Collect(ch)
+	close(ch)
+
+	values := make(map[string]float64)
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+
+		name := result.Desc().String()
+		for _, label := range metric.Label {
+			if label.GetName() != "pubkey" {
+				name += "," + label.GetName() + "=" + label.GetValue()
+			}
+		}
+		values[name] = *metric.Gauge.Value
+	}
+	return values
+}
+
+func TestBeaconValidatorsCollectError(t *testing.T) {
+	collector := NewBeaconValidators(NewClient("http://localhost", http.DefaultClient), []string{"1"}, 10)
+	ch := make(chan prometheus.Metric, 7)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 7 {
+		t.Fatalf("got %v, want 7", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestBeaconValidatorsCollect(t *testing.T) {
+	var requests int
+	server := newValidatorsServer(t, &requests)
+	defer server.Close()
+
+	collector := NewBeaconValidators(NewClient(server.URL, http.DefaultClient), []string{"2", "1", "0"}, 10)
+	values := collectBeaconValidators(t, collector)
+
+	want := map[string]float64{
+		collector.countDesc.String() + ",status=active_ongoing":          2,
+		collector.countDesc.String() + ",status=active_slashed":          1,
+		collector.countDesc.String() + ",status=exited_unslashed":        0,
+		collector.balanceDesc.String() + ",index=0":                      32000123456,
+		collector.effectiveBalanceDesc.String() + ",index=1":             32000000000,
+		collector.statusDesc.String() + ",index=2,status=active_slashed": 1,
+		collector.slashedDesc.String() + ",index=2":                      1,
+		collector.slashedDesc.String() + ",index=1":                      0,
+		collector.liveDesc.String() + ",index=0":                         1,
+		collector.liveDesc.String() + ",index=1":                         0,
+		collector.missedProposalsDesc.String() + ",index=0":              0,
+		collector.missedProposalsDesc.String() + ",index=1":              1,
+		collector.missedProposalsDesc.String() + ",index=2":              0,
+	}
+	for name, value := range want {
+		if got, ok := values[name]; !ok || got != value {
+			t.Fatalf("got %v for %v, want %v", got, name, value)
+		}
+	}
+}
+
+func TestBeaconValidatorsCollectBatchesAndCap(t *testing.T) {
+	var requests int
+	server := newValidatorsServer(t, &requests)
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 100; i++ {
+		ids = append(ids, fmt.Sprint(i+10))
+	}
+
+	collector := NewBeaconValidators(NewClient(server.URL, http.DefaultClient), ids, 5)
+	values := collectBeaconValidators(t, collector)
+
+	if requests != 2 {
+		t.Fatalf("got %v validator requests, want 2", requests)
+	}
+	if got := values[collector.countDesc.String()+",status=active_ongoing"]; got != 100 {
+		t.Fatalf("got %v, want 100", got)
+	}
+
+	var series int
+	for name := range values {
+		if strings.HasPrefix(name, collector.balanceDesc.String()) {
+			series++
+		}
+	}
+	if series != 5 {
+		t.Fatalf("got %v balance series, want 5", series)
+	}
+}
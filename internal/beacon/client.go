@@ -0,0 +1,80 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Client is a minimal client of the standard Beacon node REST API.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+type apiError struct {
+	Code    int
+	Message string
+}
+
+func (err *apiError) Error() string {
+	return fmt.Sprintf("beacon API error %d: %s", err.Code, err.Message)
+}
+
+func NewClient(url string, http *http.Client) *Client {
+	return &Client{
+		url:  strings.TrimSuffix(url, "/"),
+		http: http,
+	}
+}
+
+// Get requests path and decodes the data field of the response into result.
+func (client *Client) Get(ctx context.Context, path string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, client.url+path, nil)
+	if err != nil {
+		return err
+	}
+
+	return client.do(request, result)
+}
+
+// Post sends body encoded as JSON to path and decodes the data field of the
+// response into result.
+func (client *Client) Post(ctx context.Context, path string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.url+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	return client.do(request, result)
+}
+
+func (client *Client) do(request *http.Request, result interface{}) error {
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		apiErr := &apiError{Code: response.StatusCode, Message: response.Status}
+		_ = json.NewDecoder(response.Body).Decode(apiErr)
+		return apiErr
+	}
+
+	envelope := struct {
+		Data interface{}
+	}{result}
+	return json.NewDecoder(response.Body).Decode(&envelope)
+}
@@ -0,0 +1,50 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newServer(t *testing.T, path, response string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code": 404, "message": "not found"}`))
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func TestClientGet(t *testing.T) {
+	server := newServer(t, "/eth/v1/node/version", `{"data": {"version": "Lighthouse/v4.5.0"}}`)
+	defer server.Close()
+
+	var result versionResult
+	if err := NewClient(server.URL+"/", http.DefaultClient).Get(context.Background(), "/eth/v1/node/version", &result); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if result.Version != "Lighthouse/v4.5.0" {
+		t.Fatalf("got %v, want Lighthouse/v4.5.0", result.Version)
+	}
+}
+
+func TestClientGetAPIError(t *testing.T) {
+	server := newServer(t, "/eth/v1/node/version", `{}`)
+	defer server.Close()
+
+	var result versionResult
+	err := NewClient(server.URL, http.DefaultClient).Get(context.Background(), "/eth/v1/node/syncing", &result)
+
+	apiErr, ok := err.(*apiError)
+	if !ok {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if apiErr.Code != 404 || apiErr.Message != "not found" {
+		t.Fatalf("unexpected error %#v", apiErr)
+	}
+}
@@ -0,0 +1,48 @@
+package beacon
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NodePeerCount struct {
+	client *Client
+	desc   *prometheus.Desc
+}
+
+type peerCountResult struct {
+	Disconnected  uint64 `json:"disconnected,string"`
+	Connecting    uint64 `json:"connecting,string"`
+	Connected     uint64 `json:"connected,string"`
+	Disconnecting uint64 `json:"disconnecting,string"`
+}
+
+func NewNodePeerCount(client *Client) *NodePeerCount {
+	return &NodePeerCount{
+		client: client,
+		desc: prometheus.NewDesc(
+			"beacon_peers",
+			"number of peers of the beacon node by connection state",
+			[]string{"state"},
+			nil,
+		),
+	}
+}
+
+func (collector *NodePeerCount) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *NodePeerCount) Collect(ch chan<- prometheus.Metric) {
+	var result peerCountResult
+	if err := collector.client.Get(context.Background(), "/eth/v1/node/peer_count", &result); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(result.Connected), "connected")
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(result.Connecting), "connecting")
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(result.Disconnected), "disconnected")
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(result.Disconnecting), "disconnecting")
+}
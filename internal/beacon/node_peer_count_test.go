@@ -0,0 +1,60 @@
+package beacon
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestNodePeerCountCollectError(t *testing.T) {
+	collector := NewNodePeerCount(NewClient("http://localhost", http.DefaultClient))
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestNodePeerCountCollect(t *testing.T) {
+	server := newServer(t, "/eth/v1/node/peer_count", `{"data": {"disconnected": "12", "connecting": "3", "connected": "80", "disconnecting": "1"}}`)
+	defer server.Close()
+
+	collector := NewNodePeerCount(NewClient(server.URL, http.DefaultClient))
+	ch := make(chan prometheus.Metric, 4)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 4 {
+		t.Fatalf("got %v, want 4", got)
+	}
+
+	want := map[string]float64{"connected": 80, "connecting": 3, "disconnected": 12, "disconnecting": 1}
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		state := metric.Label[0].GetValue()
+		if got := *metric.Gauge.Value; got != want[state] {
+			t.Fatalf("got %v, want %v for %v", got, want[state], state)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package beacon
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NodeSyncing struct {
+	client         *Client
+	distanceDesc   *prometheus.Desc
+	syncingDesc    *prometheus.Desc
+	optimisticDesc *prometheus.Desc
+}
+
+type syncingResult struct {
+	HeadSlot     uint64 `json:"head_slot,string"`
+	SyncDistance uint64 `json:"sync_distance,string"`
+	IsSyncing    bool   `json:"is_syncing"`
+	IsOptimistic bool   `json:"is_optimistic"`
+}
+
+func NewNodeSyncing(client *Client) *NodeSyncing {
+	return &NodeSyncing{
+		client: client,
+		distanceDesc: prometheus.NewDesc(
+			"beacon_sync_distance",
+			"number of slots the beacon node is behind the estimated head",
+			nil,
+			nil,
+		),
+		syncingDesc: prometheus.NewDesc(
+			"beacon_syncing",
+			"whether the beacon node is syncing",
+			nil,
+			nil,
+		),
+		optimisticDesc: prometheus.NewDesc(
+			"beacon_optimistic",
+			"whether the beacon node is optimistically synced",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *NodeSyncing) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.distanceDesc
+	ch <- collector.syncingDesc
+	ch <- collector.optimisticDesc
+}
+
+func (collector *NodeSyncing) Collect(ch chan<- prometheus.Metric) {
+	var result syncingResult
+	if err := collector.client.Get(context.Background(), "/eth/v1/node/syncing", &result); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.distanceDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.syncingDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.optimisticDesc, err)
+		return
+	}
+
+	value := float64(result.SyncDistance)
+	ch <- prometheus.MustNewConstMetric(collector.distanceDesc, prometheus.GaugeValue, value)
+	ch <- prometheus.MustNewConstMetric(collector.syncingDesc, prometheus.GaugeValue, boolToFloat(result.IsSyncing))
+	ch <- prometheus.MustNewConstMetric(collector.optimisticDesc, prometheus.GaugeValue, boolToFloat(result.IsOptimistic))
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
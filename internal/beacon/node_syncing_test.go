@@ -0,0 +1,61 @@
+package beacon
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestNodeSyncingCollectError(t *testing.T) {
+	collector := NewNodeSyncing(NewClient("http://localhost", http.DefaultClient))
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestNodeSyncingCollect(t *testing.T) {
+	server := newServer(t, "/eth/v1/node/syncing", `{"data": {"head_slot": "7620000", "sync_distance": "32", "is_syncing": true, "is_optimistic": false, "el_offline": false}}`)
+	defer server.Close()
+
+	collector := NewNodeSyncing(NewClient(server.URL, http.DefaultClient))
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	for _, want := range []float64{32, 1, 0} {
+		var metric dto.Metric
+		if err := (<-ch).Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := len(metric.Label); got > 0 {
+			t.Fatalf("expected 0 labels, got %d", got)
+		}
+		if got := *metric.Gauge.Value; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package beacon
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NodeVersion struct {
+	client *Client
+	desc   *prometheus.Desc
+}
+
+type versionResult struct {
+	Version string
+}
+
+func NewNodeVersion(client *Client) *NodeVersion {
+	return &NodeVersion{
+		client: client,
+		desc: prometheus.NewDesc(
+			"beacon_node_info",
+			"client name and version of the beacon node",
+			[]string{"implementation", "version"},
+			nil,
+		),
+	}
+}
+
+func (collector *NodeVersion) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *NodeVersion) Collect(ch chan<- prometheus.Metric) {
+	var result versionResult
+	if err := collector.client.Get(context.Background(), "/eth/v1/node/version", &result); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	// Versions look like "Lighthouse/v4.5.0-441fc16/x86_64-linux".
+	implementation, version, _ := strings.Cut(result.Version, "/")
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, 1, implementation, version)
+}
@@ -0,0 +1,65 @@
+package beacon
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestNodeVersionCollectError(t *testing.T) {
+	collector := NewNodeVersion(NewClient("http://localhost", http.DefaultClient))
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestNodeVersionCollect(t *testing.T) {
+	server := newServer(t, "/eth/v1/node/version", `{"data": {"version": "Lighthouse/v4.5.0-441fc16/x86_64-linux"}}`)
+	defer server.Close()
+
+	collector := NewNodeVersion(NewClient(server.URL, http.DefaultClient))
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		labels := make(map[string]string)
+		for _, label := range metric.Label {
+			labels[label.GetName()] = label.GetValue()
+		}
+		if got := labels["implementation"]; got != "Lighthouse" {
+			t.Fatalf("got %v, want Lighthouse", got)
+		}
+		if got := labels["version"]; got != "v4.5.0-441fc16/x86_64-linux" {
+			t.Fatalf("got %v, want v4.5.0-441fc16/x86_64-linux", got)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// missingStateErrors are parts of the error messages of clients for state
+// that is not served, such as "missing trie node" of Geth or "World state
+// unavailable" of Besu.
+var missingStateErrors = []string{
+	"missing trie node",
+	"state is not available",
+	"state not available",
+	"no state available",
+	"state unavailable",
+	"historical state",
+	"pruned",
+}
+
+// ArchiveProbe periodically checks that historical state is served, to
+// detect pruned nodes among those claiming to be archive nodes.
+type ArchiveProbe struct {
+	client  follower.ClientFunc
+	address common.Address
+	blocks  []uint64
+	search  bool
+	timeout time.Duration
+
+	mu        sync.Mutex
+	probed    bool
+	success   bool
+	available map[uint64]bool
+	oldest    uint64
+	found     bool
+
+	successDesc   *prometheus.Desc
+	availableDesc *prometheus.Desc
+	oldestDesc    *prometheus.Desc
+}
+
+// NewArchiveProbe returns a probe that queries the state of address at each
+// of blocks and, if search is set, searches for the oldest block with state.
+func NewArchiveProbe(client follower.ClientFunc, address common.Address, blocks []uint64, search bool, timeout time.Duration) *ArchiveProbe {
+	return &ArchiveProbe{
+		client:    client,
+		address:   address,
+		blocks:    blocks,
+		search:    search,
+		timeout:   timeout,
+		available: make(map[uint64]bool),
+		successDesc: prometheus.NewDesc(
+			"archive_probe_success",
+			"whether the last archive probe succeeded",
+			nil,
+			nil,
+		),
+		availableDesc: prometheus.NewDesc(
+			"archive_state_available",
+			"whether the state at the block is served",
+			[]string{"block"},
+			nil,
+		),
+		oldestDesc: prometheus.NewDesc(
+			"archive_oldest_state_block",
+			"number of the oldest block whose state is served",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (probe *ArchiveProbe) Describe(ch chan<- *prometheus.Desc) {
+	ch <- probe.successDesc
+	ch <- probe.availableDesc
+	ch <- probe.oldestDesc
+}
+
+func (probe *ArchiveProbe) Collect(ch chan<- prometheus.Metric) {
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	if !probe.probed {
+		return
+	}
+
+	value := 0.0
+	if probe.success {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(probe.successDesc, prometheus.GaugeValue, value)
+
+	blocks := make([]uint64, 0, len(probe.available))
+	for block := range probe.available {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+
+	for _, block := range blocks {
+		value := 0.0
+		if probe.available[block] {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(probe.availableDesc, prometheus.GaugeValue, value, strconv.FormatUint(block, 10))
+	}
+
+	if probe.found {
+		value := float64(probe.oldest)
+		ch <- prometheus.MustNewConstMetric(probe.oldestDesc, prometheus.GaugeValue, value)
+	}
+}
+
+// Run probes every interval until ctx is done.
+func (probe *ArchiveProbe) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := probe.Probe(ctx); err != nil {
+			log.Printf("archive probe: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe checks the configured blocks and searches for the oldest state. When
+// it fails, the results of earlier probes are dropped rather than kept stale.
+func (probe *ArchiveProbe) Probe(ctx context.Context) error {
+	available, oldest, found, err := probe.probe(ctx)
+	if err != nil {
+		probe.record(false, make(map[uint64]bool), 0, false)
+		return err
+	}
+
+	probe.record(true, available, oldest, found)
+	return nil
+}
+
+func (probe *ArchiveProbe) probe(ctx context.Context) (map[uint64]bool, uint64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, probe.timeout)
+	defer cancel()
+
+	client := probe.client()
+
+	available := make(map[uint64]bool, len(probe.blocks))
+	for _, block := range probe.blocks {
+		ok, err := probe.hasState(ctx, client, block)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		available[block] = ok
+	}
+
+	if !probe.search {
+		return available, 0, false, nil
+	}
+
+	oldest, found, err := probe.searchOldest(ctx, client)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return available, oldest, found, nil
+}
+
+func (probe *ArchiveProbe) record(success bool, available map[uint64]bool, oldest uint64, found bool) {
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	probe.probed = true
+	probe.success = success
+	probe.available = available
+	probe.oldest, probe.found = oldest, found
+}
+
+// searchOldest assumes that once state is served for a block, it is served
+// for all later blocks. The genesis state is often kept by pruned nodes, so
+// the search starts at block 1.
+func (probe *ArchiveProbe) searchOldest(ctx context.Context, client *rpc.Client) (uint64, bool, error) {
+	var head hexutil.Uint64
+	if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return 0, false, err
+	}
+
+	lo, hi := uint64(1), uint64(head)
+	if lo > hi {
+		return 0, false, nil
+	}
+
+	available, err := probe.hasState(ctx, client, hi)
+	if err != nil || !available {
+		return 0, false, err
+	}
+	available, err = probe.hasState(ctx, client, lo)
+	if err != nil || available {
+		return lo, available, err
+	}
+
+	// State is missing at lo and served at hi.
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		available, err := probe.hasState(ctx, client, mid)
+		if err != nil {
+			return 0, false, err
+		}
+		if available {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi, true, nil
+}
+
+// hasState reports whether the balance and code of the address at block are
+// served. Node errors about missing state mean that it is not, while other
+// errors, such as connection failures or rate limits, are returned.
+func (probe *ArchiveProbe) hasState(ctx context.Context, client *rpc.Client, block uint64) (bool, error) {
+	number := hexutil.EncodeUint64(block)
+
+	var balance hexutil.Big
+	err := client.CallContext(ctx, &balance, "eth_getBalance", probe.address, number)
+	if err == nil {
+		var code hexutil.Bytes
+		err = client.CallContext(ctx, &code, "eth_getCode", probe.address, number)
+	}
+
+	if isMissingState(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// isMissingState reports whether err is a node error about missing state.
+func isMissingState(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+
+	message := strings.ToLower(rpcErr.Error())
+	for _, missing := range missingStateErrors {
+		if strings.Contains(message, missing) {
+			return true
+		}
+	}
+	return false
+}
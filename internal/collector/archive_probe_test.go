@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newArchiveServer serves a chain at block 1000 whose state is available
+// from block oldest on, as well as at genesis. State queries are rate limited
+// while limited is set.
+func newArchiveServer(t *testing.T, oldest uint64, limited *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+			Params []string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": "0x3e8"}`, request.ID)
+		if request.Method != "eth_blockNumber" {
+			block, err := hexutil.DecodeUint64(request.Params[1])
+			if err != nil {
+				t.Fatalf("could not decode a block number: %#v", err)
+			}
+			if limited.Load() {
+				response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32005, "message": "rate limit exceeded"}}`, request.ID)
+			} else if block != 0 && block < oldest {
+				response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32000, "message": "missing trie node"}}`, request.ID)
+			} else if request.Method == "eth_getCode" {
+				response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": "0x"}`, request.ID)
+			}
+		}
+
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func newArchiveProbe(t *testing.T, url string, blocks []uint64, search bool) *ArchiveProbe {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	return NewArchiveProbe(func() *rpc.Client { return client }, common.Address{}, blocks, search, time.Second)
+}
+
+func TestArchiveProbeProbeError(t *testing.T) {
+	probe := newArchiveProbe(t, "http://localhost", []uint64{1}, true)
+
+	err := probe.Probe(context.Background())
+	if _, ok := err.(*url.Error); !ok {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	want := map[string]float64{"archive_probe_success": 0}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestArchiveProbeProbeNodeError(t *testing.T) {
+	var limited atomic.Bool
+	rpcServer := newArchiveServer(t, 700, &limited)
+	defer rpcServer.Close()
+
+	probe := newArchiveProbe(t, rpcServer.URL, []uint64{500}, true)
+	if err := probe.Probe(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	// Node errors other than missing state fail the probe rather than
+	// report the state as missing, and earlier results are dropped.
+	limited.Store(true)
+	if err := probe.Probe(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	want := map[string]float64{"archive_probe_success": 0}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestArchiveProbeProbe(t *testing.T) {
+	for _, test := range []struct {
+		oldest uint64
+		want   map[string]float64
+	}{
+		{oldest: 1, want: map[string]float64{
+			"archive_state_available{0}":   1,
+			"archive_state_available{500}": 1,
+			"archive_state_available{900}": 1,
+			"archive_oldest_state_block":   1,
+			"archive_probe_success":        1,
+		}},
+		{oldest: 700, want: map[string]float64{
+			"archive_state_available{0}":   1,
+			"archive_state_available{500}": 0,
+			"archive_state_available{900}": 1,
+			"archive_oldest_state_block":   700,
+			"archive_probe_success":        1,
+		}},
+		{oldest: 1000, want: map[string]float64{
+			"archive_state_available{0}":   1,
+			"archive_state_available{500}": 0,
+			"archive_state_available{900}": 0,
+			"archive_oldest_state_block":   1000,
+			"archive_probe_success":        1,
+		}},
+		{oldest: 1001, want: map[string]float64{
+			"archive_state_available{0}":   1,
+			"archive_state_available{500}": 0,
+			"archive_state_available{900}": 0,
+			"archive_probe_success":        1,
+		}},
+	} {
+		rpcServer := newArchiveServer(t, test.oldest, new(atomic.Bool))
+
+		probe := newArchiveProbe(t, rpcServer.URL, []uint64{900, 0, 500}, true)
+		if err := probe.Probe(context.Background()); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		got := gather(t, probe)
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Fatalf("got %v, want %v", got, test.want)
+		}
+
+		rpcServer.Close()
+	}
+}
+
+func TestArchiveProbeProbeNoSearch(t *testing.T) {
+	rpcServer := newArchiveServer(t, 700, new(atomic.Bool))
+	defer rpcServer.Close()
+
+	probe := newArchiveProbe(t, rpcServer.URL, []uint64{500}, false)
+	if err := probe.Probe(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{"archive_state_available{500}": 0, "archive_probe_success": 1}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type BFTSignerMetrics struct {
+	rpc              *rpc.Client
+	methods          []string
+	proposedDesc     *prometheus.Desc
+	lastProposedDesc *prometheus.Desc
+}
+
+type signerMetricsResult struct {
+	Address                 common.Address
+	ProposedBlockCount      hexutil.Uint64
+	LastProposedBlockNumber hexutil.Uint64
+}
+
+// NewBFTSignerMetrics returns a collector of per-validator proposals using
+// the first of the given methods the node supports, e.g.
+// qbft_getSignerMetrics or ibft_getSignerMetrics.
+func NewBFTSignerMetrics(rpc *rpc.Client, methods ...string) *BFTSignerMetrics {
+	return &BFTSignerMetrics{
+		rpc:     rpc,
+		methods: methods,
+		proposedDesc: prometheus.NewDesc(
+			"bft_validator_proposed_blocks",
+			"number of blocks proposed by the validator in the recent window",
+			[]string{"validator"},
+			nil,
+		),
+		lastProposedDesc: prometheus.NewDesc(
+			"bft_validator_last_proposed_block",
+			"number of the last block proposed by the validator",
+			[]string{"validator"},
+			nil,
+		),
+	}
+}
+
+func (collector *BFTSignerMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.proposedDesc
+	ch <- collector.lastProposedDesc
+}
+
+func (collector *BFTSignerMetrics) Collect(ch chan<- prometheus.Metric) {
+	var result []*signerMetricsResult
+	if err := callFirst(collector.rpc, &result, collector.methods); err != nil {
+		// Nodes running another consensus engine are not an error.
+		if isMethodNotFound(err) {
+			return
+		}
+		ch <- prometheus.NewInvalidMetric(collector.proposedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.lastProposedDesc, err)
+		return
+	}
+
+	for _, signer := range result {
+		validator := signer.Address.Hex()
+		value := float64(signer.ProposedBlockCount)
+		ch <- prometheus.MustNewConstMetric(collector.proposedDesc, prometheus.GaugeValue, value, validator)
+		value = float64(signer.LastProposedBlockNumber)
+		ch <- prometheus.MustNewConstMetric(collector.lastProposedDesc, prometheus.GaugeValue, value, validator)
+	}
+}
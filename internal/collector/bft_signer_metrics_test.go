@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestBFTSignerMetricsCollectError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewBFTSignerMetrics(rpc, "qbft_getSignerMetrics")
+	ch := make(chan prometheus.Metric, 2)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestBFTSignerMetricsCollect(t *testing.T) {
+	rpcServer := newBFTServer(t, "qbft_getSignerMetrics", `[{"address": "0x7ffc57839b00206d1ad20c69a1981b489f772031", "proposedBlockCount": "0x32", "lastProposedBlockNumber": "0x5dc"}]`)
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewBFTSignerMetrics(rpc, "qbft_getSignerMetrics", "ibft_getSignerMetrics")
+	ch := make(chan prometheus.Metric, 2)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+
+	validator := common.HexToAddress("0x7ffc57839b00206d1ad20c69a1981b489f772031").Hex()
+	var metric dto.Metric
+	for _, want := range []float64{50, 1500} {
+		if err := (<-ch).Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := metric.Label[0].GetValue(); got != validator {
+			t.Fatalf("got %v, want %v", got, validator)
+		}
+		if got := *metric.Gauge.Value; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type BFTValidators struct {
+	rpc     *rpc.Client
+	methods []string
+	desc    *prometheus.Desc
+}
+
+// NewBFTValidators returns a collector that counts the validators of the
+// latest block using the first of the given methods the node supports,
+// e.g. qbft_getValidatorsByBlockNumber or istanbul_getValidators.
+func NewBFTValidators(rpc *rpc.Client, methods ...string) *BFTValidators {
+	return &BFTValidators{
+		rpc:     rpc,
+		methods: methods,
+		desc: prometheus.NewDesc(
+			"bft_validators",
+			"number of validators of the latest block",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *BFTValidators) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *BFTValidators) Collect(ch chan<- prometheus.Metric) {
+	var result []common.Address
+	if err := callFirst(collector.rpc, &result, collector.methods, "latest"); err != nil {
+		// Nodes running another consensus engine are not an error.
+		if isMethodNotFound(err) {
+			return
+		}
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	value := float64(len(result))
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
+
+// callFirst calls the first of the methods the node supports.
+func callFirst(rpc *rpc.Client, result interface{}, methods []string, args ...interface{}) error {
+	var err error
+	for _, method := range methods {
+		if err = rpc.Call(result, method, args...); !isMethodNotFound(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestBFTValidatorsCollectError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewBFTValidators(rpc, "qbft_getValidatorsByBlockNumber")
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	err = (<-ch).Write(&metric)
+	if _, ok := err.(*url.Error); !ok {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+// newBFTServer serves result for the given method and reports every other
+// method as not found.
+func newBFTServer(t *testing.T, method, result string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		response := `{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, "result": ` + result + `}`
+		if request.Method != method {
+			response = `{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, "error": {"code": -32601, "message": "Method not found"}}`
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func TestBFTValidatorsCollect(t *testing.T) {
+	rpcServer := newBFTServer(t, "ibft_getValidatorsByBlockNumber", `["0x7ffc57839b00206d1ad20c69a1981b489f772031", "0xb279182d99e65703f0076e4812653aab85fca0f0"]`)
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewBFTValidators(rpc, "qbft_getValidatorsByBlockNumber", "ibft_getValidatorsByBlockNumber")
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	if err := (<-ch).Write(&metric); err != nil {
+		t.Fatalf("expected metric, got %#v", err)
+	}
+	if got := *metric.Gauge.Value; got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+}
+
+func TestBFTValidatorsCollectUnsupported(t *testing.T) {
+	rpcServer := newBFTServer(t, "clique_getSigners", `[]`)
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewBFTValidators(rpc, "qbft_getValidatorsByBlockNumber", "ibft_getValidatorsByBlockNumber")
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+}
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"log"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClientCollector collects the client specific collectors built once the
+// client is detected. Detection is retried on every scrape until it succeeds,
+// so that a node that is down at startup still gets its collectors. The
+// collectors are unknown beforehand, so ClientCollector is unchecked.
+type ClientCollector struct {
+	rpc   *rpc.Client
+	build func(client Client) []prometheus.Collector
+
+	mu         sync.Mutex
+	detected   bool
+	collectors []prometheus.Collector
+}
+
+func NewClientCollector(rpc *rpc.Client, build func(client Client) []prometheus.Collector) *ClientCollector {
+	return &ClientCollector{
+		rpc:   rpc,
+		build: build,
+	}
+}
+
+func (collector *ClientCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (collector *ClientCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.Lock()
+	if !collector.detected {
+		client, err := DetectClient(collector.rpc)
+		if err != nil {
+			log.Printf("could not detect the client: %v", err)
+		} else {
+			collector.detected = true
+			collector.collectors = collector.build(client)
+		}
+	}
+	collectors := collector.collectors
+	collector.mu.Unlock()
+
+	for _, c := range collectors {
+		c.Collect(ch)
+	}
+}
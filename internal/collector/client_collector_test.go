@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestClientCollectorRetriesDetection(t *testing.T) {
+	calls := 0
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := w.Write([]byte(`{"result": "besu/v23.4.0/linux-x86_64/openjdk-java-17"}`)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	var detected []Client
+	collector := NewClientCollector(rpc, func(client Client) []prometheus.Collector {
+		detected = append(detected, client)
+		return []prometheus.Collector{prometheus.NewGauge(prometheus.GaugeOpts{Name: "besu", Help: "besu"})}
+	})
+
+	// The node is down on the first scrape, and the client is detected only
+	// once it is up.
+	for _, want := range []int{0, 1, 1} {
+		ch := make(chan prometheus.Metric, 1)
+		collector.Collect(ch)
+		close(ch)
+
+		if got := len(ch); got != want {
+			t.Fatalf("got %v metrics, want %v", got, want)
+		}
+	}
+
+	if calls != 2 {
+		t.Fatalf("got %v calls, want 2", calls)
+	}
+	if len(detected) != 1 || detected[0] != ClientBesu {
+		t.Fatalf("got %v, want [%v]", detected, ClientBesu)
+	}
+}
@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type CliqueProposals struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewCliqueProposals(rpc *rpc.Client) *CliqueProposals {
+	return &CliqueProposals{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"clique_proposals",
+			"number of signer proposals of the node by vote",
+			[]string{"vote"},
+			nil,
+		),
+	}
+}
+
+func (collector *CliqueProposals) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *CliqueProposals) Collect(ch chan<- prometheus.Metric) {
+	var result map[common.Address]bool
+	if err := collector.rpc.Call(&result, "clique_proposals"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	var authorize, deauthorize int
+	for _, auth := range result {
+		if auth {
+			authorize++
+		} else {
+			deauthorize++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(authorize), "authorize")
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(deauthorize), "deauthorize")
+}
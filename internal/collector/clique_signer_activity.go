@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CliqueSignerActivity flags configured signers that have not sealed any of
+// the last followed blocks. Signers of followed blocks are looked up with
+// clique_getSigner. Blocks whose signer cannot be looked up are skipped, so
+// that the follower is not held up by nodes without the method.
+type CliqueSignerActivity struct {
+	client       follower.ClientFunc
+	signers      []common.Address
+	window       uint64
+	lastDesc     *prometheus.Desc
+	inactiveDesc *prometheus.Desc
+
+	mu    sync.Mutex
+	first uint64
+	head  uint64
+	last  map[common.Address]uint64
+	err   error
+}
+
+func NewCliqueSignerActivity(client follower.ClientFunc, signers []common.Address, window uint64) *CliqueSignerActivity {
+	return &CliqueSignerActivity{
+		client:  client,
+		signers: signers,
+		window:  window,
+		last:    make(map[common.Address]uint64),
+		lastDesc: prometheus.NewDesc(
+			"clique_signer_last_block",
+			"number of the most recent block sealed by the signer",
+			[]string{"signer"},
+			nil,
+		),
+		inactiveDesc: prometheus.NewDesc(
+			"clique_signer_inactive",
+			"whether the signer has not sealed any of the recent blocks",
+			[]string{"signer"},
+			nil,
+		),
+	}
+}
+
+func (collector *CliqueSignerActivity) HandleBlock(ctx context.Context, block *follower.Block) error {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	number := uint64(block.Number)
+	if collector.head != 0 && number <= collector.head {
+		return nil
+	}
+
+	var signer common.Address
+	if err := collector.client().CallContext(ctx, &signer, "clique_getSigner", hexutil.EncodeUint64(number)); err != nil {
+		// Failures are logged once until a lookup succeeds again.
+		if collector.err == nil {
+			log.Printf("could not look up the signer of block %d: %v", number, err)
+		}
+		collector.err = err
+		return nil
+	}
+	collector.err = nil
+
+	if collector.head == 0 {
+		collector.first = number
+	}
+	collector.head = number
+	collector.last[signer] = number
+
+	return nil
+}
+
+func (collector *CliqueSignerActivity) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.lastDesc
+	ch <- collector.inactiveDesc
+}
+
+func (collector *CliqueSignerActivity) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.inactiveDesc, collector.err)
+		return
+	}
+
+	// Nothing is known until a block has been followed.
+	if collector.head == 0 {
+		return
+	}
+
+	for _, signer := range collector.signers {
+		// Signers that have not sealed a block since the exporter started
+		// are inactive once a whole window of blocks has been followed.
+		idle := collector.head - collector.first + 1
+		if last, ok := collector.last[signer]; ok {
+			ch <- prometheus.MustNewConstMetric(collector.lastDesc, prometheus.GaugeValue, float64(last), signer.Hex())
+			idle = collector.head - last
+		}
+
+		value := 0.0
+		if idle >= collector.window {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(collector.inactiveDesc, prometheus.GaugeValue, value, signer.Hex())
+	}
+}
@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCliqueSignerActivityCollect(t *testing.T) {
+	a := common.HexToAddress("0x000000000000000000000000000000000000000a")
+	b := common.HexToAddress("0x000000000000000000000000000000000000000b")
+	c := common.HexToAddress("0x000000000000000000000000000000000000000c")
+
+	// Blocks are sealed by a and b in turns.
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Params []string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		signer := a
+		if hexutil.MustDecodeUint64(request.Params[0])%2 == 1 {
+			signer = b
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": "%s"}`, request.ID, signer.Hex())
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	client, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewCliqueSignerActivity(func() *rpc.Client { return client }, []common.Address{a, b, c}, 3)
+
+	collect := func() map[string]float64 {
+		ch := make(chan prometheus.Metric, 6)
+
+		collector.Collect(ch)
+		close(ch)
+
+		values := make(map[string]float64)
+		for result := range ch {
+			var metric dto.Metric
+			if err := result.Write(&metric); err != nil {
+				t.Fatalf("expected metric, got %#v", err)
+			}
+			values[result.Desc().String()+","+metric.Label[0].GetValue()] = *metric.Gauge.Value
+		}
+		return values
+	}
+
+	if got := len(collect()); got != 0 {
+		t.Fatalf("got %v metrics, want 0", got)
+	}
+
+	for number := uint64(10); number < 12; number++ {
+		if err := collector.HandleBlock(context.Background(), &follower.Block{Number: hexutil.Uint64(number)}); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	values := collect()
+	if got := values[collector.lastDesc.String()+","+b.Hex()]; got != 11 {
+		t.Fatalf("got %v, want 11", got)
+	}
+	if got := values[collector.inactiveDesc.String()+","+c.Hex()]; got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+
+	if err := collector.HandleBlock(context.Background(), &follower.Block{Number: 12}); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	values = collect()
+	want := map[string]float64{
+		collector.lastDesc.String() + "," + a.Hex():     12,
+		collector.lastDesc.String() + "," + b.Hex():     11,
+		collector.inactiveDesc.String() + "," + a.Hex(): 0,
+		collector.inactiveDesc.String() + "," + b.Hex(): 0,
+		collector.inactiveDesc.String() + "," + c.Hex(): 1,
+	}
+	if len(values) != len(want) {
+		t.Fatalf("got %v, want %v", values, want)
+	}
+	for name, value := range want {
+		if got := values[name]; got != value {
+			t.Fatalf("got %v for %v, want %v", got, name, value)
+		}
+	}
+}
+
+func TestCliqueSignerActivityHandleBlockError(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID json.RawMessage
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32601, "message": "Method not found"}}`, request.ID)
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	client, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewCliqueSignerActivity(func() *rpc.Client { return client }, []common.Address{{}}, 3)
+
+	// The block is skipped instead of being retried by the follower.
+	if err := collector.HandleBlock(context.Background(), &follower.Block{Number: 10}); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	ch := make(chan prometheus.Metric, 2)
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v metrics, want 1", got)
+	}
+	var metric dto.Metric
+	if err := (<-ch).Write(&metric); err == nil {
+		t.Fatalf("expected error, got %v", &metric)
+	}
+}
@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type CliqueSigners struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewCliqueSigners(rpc *rpc.Client) *CliqueSigners {
+	return &CliqueSigners{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"clique_signers",
+			"number of authorized signers",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *CliqueSigners) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *CliqueSigners) Collect(ch chan<- prometheus.Metric) {
+	var result []common.Address
+	if err := collector.rpc.Call(&result, "clique_getSigners", "latest"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	value := float64(len(result))
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
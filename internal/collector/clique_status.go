@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type CliqueStatus struct {
+	rpc              *rpc.Client
+	inturnDesc       *prometheus.Desc
+	blocksDesc       *prometheus.Desc
+	signerBlocksDesc *prometheus.Desc
+}
+
+type cliqueStatusResult struct {
+	InturnPercent  float64
+	NumBlocks      uint64
+	SealerActivity map[common.Address]uint64
+}
+
+func NewCliqueStatus(rpc *rpc.Client) *CliqueStatus {
+	return &CliqueStatus{
+		rpc: rpc,
+		inturnDesc: prometheus.NewDesc(
+			"clique_inturn_percent",
+			"percentage of blocks in the recent window sealed by the in-turn signer",
+			nil,
+			nil,
+		),
+		blocksDesc: prometheus.NewDesc(
+			"clique_status_blocks",
+			"number of blocks in the recent window",
+			nil,
+			nil,
+		),
+		signerBlocksDesc: prometheus.NewDesc(
+			"clique_signer_blocks",
+			"number of blocks sealed by the signer in the recent window",
+			[]string{"signer"},
+			nil,
+		),
+	}
+}
+
+func (collector *CliqueStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.inturnDesc
+	ch <- collector.blocksDesc
+	ch <- collector.signerBlocksDesc
+}
+
+func (collector *CliqueStatus) Collect(ch chan<- prometheus.Metric) {
+	var result *cliqueStatusResult
+	if err := collector.rpc.Call(&result, "clique_status"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.inturnDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.blocksDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.signerBlocksDesc, err)
+		return
+	}
+
+	value := result.InturnPercent
+	ch <- prometheus.MustNewConstMetric(collector.inturnDesc, prometheus.GaugeValue, value)
+	value = float64(result.NumBlocks)
+	ch <- prometheus.MustNewConstMetric(collector.blocksDesc, prometheus.GaugeValue, value)
+
+	for signer, count := range result.SealerActivity {
+		ch <- prometheus.MustNewConstMetric(collector.signerBlocksDesc, prometheus.GaugeValue, float64(count), signer.Hex())
+	}
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gather registers c with a pedantic registry, which fails the test on
+// invalid metrics, and returns metric values by name and label values, such
+// as "archive_state_available{500}". Histograms are returned as their
+// sample count and sum with the "_count" and "_sum" suffixes.
+func gather(t *testing.T, c prometheus.Collector) map[string]float64 {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			name := family.GetName()
+			if len(metric.Label) > 0 {
+				var labels []string
+				for _, label := range metric.Label {
+					labels = append(labels, label.GetValue())
+				}
+				name += "{" + strings.Join(labels, ",") + "}"
+			}
+
+			switch {
+			case metric.Histogram != nil:
+				values[name+"_count"] = float64(metric.Histogram.GetSampleCount())
+				values[name+"_sum"] = metric.Histogram.GetSampleSum()
+			case metric.Counter != nil:
+				values[name] = metric.Counter.GetValue()
+			default:
+				values[name] = metric.Gauge.GetValue()
+			}
+		}
+	}
+	return values
+}
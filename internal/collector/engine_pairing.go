@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/beacon"
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EnginePairing cross-checks the head of the execution client with the
+// execution payload header of the head of its paired consensus client. The
+// head is requested blinded, which leaves out its transactions. The age of the
+// execution client head is measured from when the exporter saw it change, as
+// block timestamps also grow with missed slots.
+type EnginePairing struct {
+	client    follower.ClientFunc
+	beacon    *beacon.Client
+	now       func() time.Time
+	matchDesc *prometheus.Desc
+	lagDesc   *prometheus.Desc
+	ageDesc   *prometheus.Desc
+
+	mu             sync.Mutex
+	secondsPerSlot uint64
+	headNumber     uint64
+	advancedAt     time.Time
+}
+
+var (
+	errNoExecutionPayload = errors.New("consensus head has no execution payload")
+	errInvalidSpec        = errors.New("consensus spec has no SECONDS_PER_SLOT")
+)
+
+type blindedBlockResult struct {
+	Message struct {
+		Slot uint64 `json:"slot,string"`
+		Body struct {
+			ExecutionPayloadHeader *struct {
+				BlockNumber uint64      `json:"block_number,string"`
+				BlockHash   common.Hash `json:"block_hash"`
+				Timestamp   uint64      `json:"timestamp,string"`
+			} `json:"execution_payload_header"`
+		}
+	}
+}
+
+type specResult struct {
+	SecondsPerSlot uint64 `json:"SECONDS_PER_SLOT,string"`
+}
+
+// NewEnginePairing returns a collector comparing the active execution client
+// returned by client with the consensus client.
+func NewEnginePairing(client follower.ClientFunc, beacon *beacon.Client) *EnginePairing {
+	return &EnginePairing{
+		client: client,
+		beacon: beacon,
+		now:    time.Now,
+		matchDesc: prometheus.NewDesc(
+			"engine_head_match",
+			"whether the execution client head matches the execution payload of the consensus client head",
+			nil,
+			nil,
+		),
+		lagDesc: prometheus.NewDesc(
+			"engine_head_slot_lag",
+			"number of slots the execution client head is behind the consensus client head",
+			nil,
+			nil,
+		),
+		ageDesc: prometheus.NewDesc(
+			"engine_execution_head_age_seconds",
+			"seconds since the execution client head last advanced",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *EnginePairing) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.matchDesc
+	ch <- collector.lagDesc
+	ch <- collector.ageDesc
+}
+
+func (collector *EnginePairing) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	head, err := getBlockByNumber(ctx, collector.client(), "latest")
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.matchDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.lagDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.ageDesc, err)
+		return
+	}
+
+	value := collector.advance(uint64(head.Number)).Seconds()
+	ch <- prometheus.MustNewConstMetric(collector.ageDesc, prometheus.GaugeValue, value)
+
+	var block blindedBlockResult
+	if err := collector.beacon.Get(ctx, "/eth/v1/beacon/blinded_blocks/head", &block); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.matchDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.lagDesc, err)
+		return
+	}
+
+	payload := block.Message.Body.ExecutionPayloadHeader
+	if payload == nil {
+		err := errNoExecutionPayload
+		ch <- prometheus.NewInvalidMetric(collector.matchDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.lagDesc, err)
+		return
+	}
+
+	value = 0
+	if payload.BlockHash == head.Hash {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(collector.matchDesc, prometheus.GaugeValue, value)
+
+	secondsPerSlot, err := collector.getSecondsPerSlot(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.lagDesc, err)
+		return
+	}
+
+	value = 0
+	if payload.Timestamp > uint64(head.Timestamp) {
+		value = float64((payload.Timestamp - uint64(head.Timestamp)) / secondsPerSlot)
+	}
+	ch <- prometheus.MustNewConstMetric(collector.lagDesc, prometheus.GaugeValue, value)
+}
+
+// advance records the number of the execution client head and returns the
+// time since it last changed.
+func (collector *EnginePairing) advance(number uint64) time.Duration {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	now := collector.now()
+	if collector.advancedAt.IsZero() || number != collector.headNumber {
+		collector.headNumber = number
+		collector.advancedAt = now
+	}
+	return now.Sub(collector.advancedAt)
+}
+
+// getSecondsPerSlot returns the slot duration from the chain spec of the
+// consensus client. It never changes, so it is only requested once.
+func (collector *EnginePairing) getSecondsPerSlot(ctx context.Context) (uint64, error) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.secondsPerSlot > 0 {
+		return collector.secondsPerSlot, nil
+	}
+
+	var spec specResult
+	if err := collector.beacon.Get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+		return 0, err
+	}
+	if spec.SecondsPerSlot == 0 {
+		return 0, errInvalidSpec
+	}
+
+	collector.secondsPerSlot = spec.SecondsPerSlot
+	return collector.secondsPerSlot, nil
+}
@@ -0,0 +1,147 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/beacon"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func newBeaconServer(t *testing.T, hash string, timestamp uint64) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte(`{"data": {"SECONDS_PER_SLOT": "12", "SLOTS_PER_EPOCH": "32"}}`)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	})
+	mux.HandleFunc("/eth/v1/beacon/blinded_blocks/head", func(w http.ResponseWriter, r *http.Request) {
+		response := fmt.Sprintf(`{"version": "capella", "data": {"message": {"slot": "100", "body": {"execution_payload_header": {"block_number": "2", "block_hash": "%s", "timestamp": "%d"}}}}}`, hash, timestamp)
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func collectEnginePairing(t *testing.T, collector *EnginePairing, now int64) []float64 {
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.now = func() time.Time { return time.Unix(now, 0) }
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	var values []float64
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		values = append(values, *metric.Gauge.Value)
+	}
+	return values
+}
+
+func TestEnginePairingCollectError(t *testing.T) {
+	client, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewEnginePairing(func() *rpc.Client { return client }, beacon.NewClient("http://localhost", http.DefaultClient))
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestEnginePairingCollect(t *testing.T) {
+	el := newChainServer(t, hash0, hash1, hash2)
+	defer el.Close()
+	cl := newBeaconServer(t, hash2, 24)
+	defer cl.Close()
+
+	node := dialNode(t, "el", el.URL)
+	collector := NewEnginePairing(func() *rpc.Client { return node.RPC }, beacon.NewClient(cl.URL, http.DefaultClient))
+
+	values := collectEnginePairing(t, collector, 30)
+	if got := values[0]; got != 0 {
+		t.Fatalf("got age %v, want 0", got)
+	}
+	if got := values[1]; got != 1 {
+		t.Fatalf("got match %v, want 1", got)
+	}
+	if got := values[2]; got != 0 {
+		t.Fatalf("got lag %v, want 0", got)
+	}
+}
+
+func TestEnginePairingCollectBehind(t *testing.T) {
+	el := newChainServer(t, hash0)
+	defer el.Close()
+	cl := newBeaconServer(t, hash2, 24)
+	defer cl.Close()
+
+	node := dialNode(t, "el", el.URL)
+	collector := NewEnginePairing(func() *rpc.Client { return node.RPC }, beacon.NewClient(cl.URL, http.DefaultClient))
+
+	values := collectEnginePairing(t, collector, 30)
+	if got := values[0]; got != 0 {
+		t.Fatalf("got age %v, want 0", got)
+	}
+	if got := values[1]; got != 0 {
+		t.Fatalf("got match %v, want 0", got)
+	}
+	if got := values[2]; got != 2 {
+		t.Fatalf("got lag %v, want 2", got)
+	}
+}
+
+func TestEnginePairingCollectMissedSlot(t *testing.T) {
+	before := newChainServer(t, hash0, hash1)
+	defer before.Close()
+	after := newChainServer(t, hash0, hash1, hash2)
+	defer after.Close()
+	cl := newBeaconServer(t, hash2, 24)
+	defer cl.Close()
+
+	node := dialNode(t, "el", before.URL)
+	collector := NewEnginePairing(func() *rpc.Client { return node.RPC }, beacon.NewClient(cl.URL, http.DefaultClient))
+	collectEnginePairing(t, collector, 20)
+
+	// The head advances to the block of slot 24.
+	node = dialNode(t, "el", after.URL)
+	if got := collectEnginePairing(t, collector, 27)[0]; got != 0 {
+		t.Fatalf("got age %v, want 0", got)
+	}
+
+	// The slot at 36 is missed, which does not age the head by its timestamp.
+	if got := collectEnginePairing(t, collector, 40)[0]; got != 13 {
+		t.Fatalf("got age %v, want 13", got)
+	}
+}
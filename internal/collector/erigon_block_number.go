@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ErigonBlockNumber struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewErigonBlockNumber(rpc *rpc.Client) *ErigonBlockNumber {
+	return &ErigonBlockNumber{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"erigon_block_number",
+			"number of the latest executed block",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *ErigonBlockNumber) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *ErigonBlockNumber) Collect(ch chan<- prometheus.Metric) {
+	var result hexutil.Uint64
+	if err := collector.rpc.Call(&result, "erigon_blockNumber"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	value := float64(result)
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
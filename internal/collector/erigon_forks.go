@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ErigonForks struct {
+	rpc         *rpc.Client
+	forksDesc   *prometheus.Desc
+	genesisDesc *prometheus.Desc
+}
+
+type forksResult struct {
+	Genesis     common.Hash
+	HeightForks []uint64
+	TimeForks   []uint64
+}
+
+func NewErigonForks(rpc *rpc.Client) *ErigonForks {
+	return &ErigonForks{
+		rpc: rpc,
+		forksDesc: prometheus.NewDesc(
+			"erigon_forks",
+			"number of forks configured for the chain by activation type",
+			[]string{"type"},
+			nil,
+		),
+		genesisDesc: prometheus.NewDesc(
+			"erigon_genesis_info",
+			"hash of the genesis block of the chain",
+			[]string{"hash"},
+			nil,
+		),
+	}
+}
+
+func (collector *ErigonForks) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.forksDesc
+	ch <- collector.genesisDesc
+}
+
+func (collector *ErigonForks) Collect(ch chan<- prometheus.Metric) {
+	var result *forksResult
+	if err := collector.rpc.Call(&result, "erigon_forks"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.forksDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.genesisDesc, err)
+		return
+	}
+
+	value := float64(len(result.HeightForks))
+	ch <- prometheus.MustNewConstMetric(collector.forksDesc, prometheus.GaugeValue, value, "block")
+	value = float64(len(result.TimeForks))
+	ch <- prometheus.MustNewConstMetric(collector.forksDesc, prometheus.GaugeValue, value, "time")
+
+	ch <- prometheus.MustNewConstMetric(collector.genesisDesc, prometheus.GaugeValue, 1, result.Genesis.Hex())
+}
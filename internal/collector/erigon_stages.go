@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ErigonStages struct {
+	rpc          *rpc.Client
+	progressDesc *prometheus.Desc
+	currentDesc  *prometheus.Desc
+}
+
+type stagesResult struct {
+	Stages []*stageResult
+}
+
+type stageResult struct {
+	StageName   string         `json:"stage_name"`
+	BlockNumber hexutil.Uint64 `json:"block_number"`
+}
+
+func NewErigonStages(rpc *rpc.Client) *ErigonStages {
+	return &ErigonStages{
+		rpc: rpc,
+		progressDesc: prometheus.NewDesc(
+			"erigon_stage_progress",
+			"number of the block the staged sync stage has processed up to",
+			[]string{"stage"},
+			nil,
+		),
+		currentDesc: prometheus.NewDesc(
+			"erigon_stage_current",
+			"whether the staged sync stage is the one in progress",
+			[]string{"stage"},
+			nil,
+		),
+	}
+}
+
+func (collector *ErigonStages) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.progressDesc
+	ch <- collector.currentDesc
+}
+
+func (collector *ErigonStages) Collect(ch chan<- prometheus.Metric) {
+	var raw json.RawMessage
+	if err := collector.rpc.Call(&raw, "eth_syncing"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.progressDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.currentDesc, err)
+		return
+	}
+
+	// Stages are only reported while syncing.
+	var syncing bool
+	if err := json.Unmarshal(raw, &syncing); err == nil {
+		return
+	}
+
+	var result *stagesResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.progressDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.currentDesc, err)
+		return
+	}
+
+	// Stages run in order, each one up to the progress of the previous, so
+	// the first stage behind the most advanced one is in progress.
+	var highest hexutil.Uint64
+	for _, stage := range result.Stages {
+		if stage.BlockNumber > highest {
+			highest = stage.BlockNumber
+		}
+	}
+
+	var found bool
+	for _, stage := range result.Stages {
+		value := float64(stage.BlockNumber)
+		ch <- prometheus.MustNewConstMetric(collector.progressDesc, prometheus.GaugeValue, value, stage.StageName)
+
+		value = 0.0
+		if !found && stage.BlockNumber < highest {
+			found = true
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(collector.currentDesc, prometheus.GaugeValue, value, stage.StageName)
+	}
+}
@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestErigonStagesCollectError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewErigonStages(rpc)
+	ch := make(chan prometheus.Metric, 2)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestErigonStagesCollect(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": {"currentBlock": "0x0", "highestBlock": "0x1312d00", "stages": [{"stage_name": "Snapshots", "block_number": "0x1312d00"}, {"stage_name": "Headers", "block_number": "0x1312d00"}, {"stage_name": "Bodies", "block_number": "0x1000"}, {"stage_name": "Execution", "block_number": "0x0"}]}}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewErigonStages(rpc)
+	ch := make(chan prometheus.Metric, 8)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 8 {
+		t.Fatalf("got %v, want 8", got)
+	}
+
+	var metric dto.Metric
+	for _, want := range []struct {
+		progress float64
+		current  float64
+	}{
+		{20000000, 0},
+		{20000000, 0},
+		{4096, 1},
+		{0, 0},
+	} {
+		if err := (<-ch).Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := *metric.Gauge.Value; got != want.progress {
+			t.Fatalf("got %v, want %v", got, want.progress)
+		}
+		if err := (<-ch).Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := *metric.Gauge.Value; got != want.current {
+			t.Fatalf("got %v, want %v for %v", got, want.current, metric.Label[0].GetValue())
+		}
+	}
+}
+
+func TestErigonStagesCollectNotSyncing(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": false}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewErigonStages(rpc)
+	ch := make(chan prometheus.Metric, 2)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+}
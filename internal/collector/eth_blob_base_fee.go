@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errCodeMethodNotFound is the JSON-RPC error code of unsupported methods.
+const errCodeMethodNotFound = -32601
+
+type EthBlobBaseFee struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewEthBlobBaseFee(rpc *rpc.Client) *EthBlobBaseFee {
+	return &EthBlobBaseFee{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"eth_blob_base_fee",
+			"current blob base fee per blob gas in wei",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *EthBlobBaseFee) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *EthBlobBaseFee) Collect(ch chan<- prometheus.Metric) {
+	var result hexutil.Big
+	if err := collector.rpc.Call(&result, "eth_blobBaseFee"); err != nil {
+		// Clients without blob support are not an error.
+		if isMethodNotFound(err) {
+			return
+		}
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	i := (*big.Int)(&result)
+	value, _ := new(big.Float).SetInt(i).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
+
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == errCodeMethodNotFound
+}
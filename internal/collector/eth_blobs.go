@@ -0,0 +1,242 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Blob fee parameters of EIP-4844 and EIP-7691.
+const (
+	gasPerBlob                = 1 << 17
+	minBaseFeePerBlobGas      = 1
+	blobBaseFeeUpdateFraction = 3338477
+
+	// pragueBlobBaseFeeUpdateFraction applies to blocks with a requests
+	// hash, which was introduced in the same fork.
+	pragueBlobBaseFeeUpdateFraction = 5007716
+)
+
+// BlobFork is the blob base fee update fraction in effect from the fork at
+// Timestamp on.
+type BlobFork struct {
+	Timestamp      uint64
+	UpdateFraction uint64
+}
+
+// ethConfigResult is the fork configuration returned by eth_config as
+// specified by EIP-7910. Only the fields used are decoded.
+type ethConfigResult struct {
+	Current *ethForkConfig
+	Next    *ethForkConfig
+}
+
+type ethForkConfig struct {
+	ActivationTime uint64
+	BlobSchedule   *struct {
+		BaseFeeUpdateFraction uint64
+	}
+}
+
+// EthBlobs exports blob market metrics of the most recent block followed
+// and a histogram of blobs per block.
+type EthBlobs struct {
+	client        follower.ClientFunc
+	schedule      []BlobFork
+	gasUsedDesc   *prometheus.Desc
+	excessGasDesc *prometheus.Desc
+	blobsDesc     *prometheus.Desc
+	baseFeeDesc   *prometheus.Desc
+	histogram     prometheus.Histogram
+
+	// config is the last result of eth_config and unsupported reports whether the
+	// node does not serve it. They are only used by HandleBlock.
+	config      *ethConfigResult
+	unsupported bool
+
+	mu       sync.Mutex
+	last     uint64
+	block    *follower.Block
+	fraction uint64
+}
+
+// NewEthBlobs returns a collector deriving blob base fees with the update
+// fractions of schedule, or of eth_config if schedule is empty. Nodes not
+// serving eth_config are assumed to follow the Cancun and Prague schedule.
+func NewEthBlobs(client follower.ClientFunc, schedule []BlobFork) *EthBlobs {
+	schedule = append([]BlobFork(nil), schedule...)
+	sort.Slice(schedule, func(i, j int) bool { return schedule[i].Timestamp < schedule[j].Timestamp })
+
+	return &EthBlobs{
+		client:   client,
+		schedule: schedule,
+		gasUsedDesc: prometheus.NewDesc(
+			"eth_block_blob_gas_used",
+			"blob gas used by the most recent block",
+			nil,
+			nil,
+		),
+		excessGasDesc: prometheus.NewDesc(
+			"eth_block_excess_blob_gas",
+			"excess blob gas of the most recent block",
+			nil,
+			nil,
+		),
+		blobsDesc: prometheus.NewDesc(
+			"eth_block_blobs",
+			"number of blobs in the most recent block",
+			nil,
+			nil,
+		),
+		baseFeeDesc: prometheus.NewDesc(
+			"eth_block_blob_base_fee",
+			"blob base fee per blob gas of the most recent block in wei derived from its excess blob gas",
+			nil,
+			nil,
+		),
+		histogram: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "eth_blobs_per_block",
+			Help:    "number of blobs per block",
+			Buckets: []float64{0, 1, 2, 3, 4, 5, 6, 9, 12, 15, 21},
+		}),
+	}
+}
+
+func (collector *EthBlobs) HandleBlock(ctx context.Context, block *follower.Block) error {
+	collector.mu.Lock()
+	processed := collector.block != nil && uint64(block.Number) <= collector.last
+	collector.mu.Unlock()
+
+	if processed {
+		return nil
+	}
+
+	// Blocks before the blob fork have no blob fields.
+	var fraction uint64
+	if block.BlobGasUsed != nil {
+		collector.histogram.Observe(float64(countBlobs(block)))
+		fraction = collector.updateFraction(ctx, block)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	collector.last = uint64(block.Number)
+	collector.block = block
+	collector.fraction = fraction
+
+	return nil
+}
+
+// updateFraction returns the blob base fee update fraction of block, or 0 if
+// it is unknown.
+func (collector *EthBlobs) updateFraction(ctx context.Context, block *follower.Block) uint64 {
+	timestamp := uint64(block.Timestamp)
+
+	if len(collector.schedule) > 0 {
+		var fraction uint64
+		for _, fork := range collector.schedule {
+			if timestamp >= fork.Timestamp {
+				fraction = fork.UpdateFraction
+			}
+		}
+		return fraction
+	}
+
+	if !collector.unsupported {
+		// The configuration is refreshed once the next fork activates.
+		config := collector.config
+		if config == nil || config.Next != nil && timestamp >= config.Next.ActivationTime {
+			var result *ethConfigResult
+			err := collector.client().CallContext(ctx, &result, "eth_config")
+			switch {
+			case isMethodNotFound(err):
+				collector.unsupported = true
+			case err != nil:
+				// Other handlers are not held up by a failing node.
+				log.Printf("could not get the blob schedule: %v", err)
+				return 0
+			case result == nil || result.Current == nil:
+				log.Printf("could not get the blob schedule: empty eth_config")
+				return 0
+			default:
+				collector.config = result
+			}
+		}
+
+		if config := collector.config; !collector.unsupported {
+			for _, fork := range []*ethForkConfig{config.Next, config.Current} {
+				if fork != nil && fork.BlobSchedule != nil && timestamp >= fork.ActivationTime {
+					return fork.BlobSchedule.BaseFeeUpdateFraction
+				}
+			}
+			// Blocks of earlier forks are not described by eth_config.
+			return 0
+		}
+	}
+
+	if block.RequestsHash != nil {
+		return pragueBlobBaseFeeUpdateFraction
+	}
+	return blobBaseFeeUpdateFraction
+}
+
+func (collector *EthBlobs) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.gasUsedDesc
+	ch <- collector.excessGasDesc
+	ch <- collector.blobsDesc
+	ch <- collector.baseFeeDesc
+	collector.histogram.Describe(ch)
+}
+
+func (collector *EthBlobs) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.Lock()
+	block := collector.block
+	fraction := collector.fraction
+	collector.mu.Unlock()
+
+	collector.histogram.Collect(ch)
+
+	if block == nil || block.BlobGasUsed == nil || block.ExcessBlobGas == nil {
+		return
+	}
+
+	value := float64(*block.BlobGasUsed)
+	ch <- prometheus.MustNewConstMetric(collector.gasUsedDesc, prometheus.GaugeValue, value)
+	value = float64(*block.ExcessBlobGas)
+	ch <- prometheus.MustNewConstMetric(collector.excessGasDesc, prometheus.GaugeValue, value)
+	value = float64(countBlobs(block))
+	ch <- prometheus.MustNewConstMetric(collector.blobsDesc, prometheus.GaugeValue, value)
+
+	if fraction == 0 {
+		return
+	}
+	fee := fakeExponential(big.NewInt(minBaseFeePerBlobGas), new(big.Int).SetUint64(uint64(*block.ExcessBlobGas)), new(big.Int).SetUint64(fraction))
+	value, _ = new(big.Float).SetInt(fee).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.baseFeeDesc, prometheus.GaugeValue, value)
+}
+
+func countBlobs(block *follower.Block) uint64 {
+	return uint64(*block.BlobGasUsed) / gasPerBlob
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) as
+// specified by EIP-4844.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	output := new(big.Int)
+	accum := new(big.Int).Mul(factor, denominator)
+
+	for i := int64(1); accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, new(big.Int).Mul(denominator, big.NewInt(i)))
+	}
+
+	return output.Div(output, denominator)
+}
@@ -0,0 +1,262 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func newBlobBlock(number, blobs, excess uint64) *follower.Block {
+	gasUsed := hexutil.Uint64(blobs * gasPerBlob)
+	excessGas := hexutil.Uint64(excess)
+	return &follower.Block{
+		Number:        hexutil.Uint64(number),
+		BlobGasUsed:   &gasUsed,
+		ExcessBlobGas: &excessGas,
+	}
+}
+
+// blobBaseFee returns the eth_block_blob_base_fee value collected, or -1 if
+// none is.
+func blobBaseFee(t *testing.T, collector *EthBlobs) float64 {
+	ch := make(chan prometheus.Metric, 5)
+
+	collector.Collect(ch)
+	close(ch)
+
+	var metric dto.Metric
+	for result := range ch {
+		if result.Desc() != collector.baseFeeDesc {
+			continue
+		}
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		return *metric.Gauge.Value
+	}
+	return -1
+}
+
+func TestEthBlobsCollectNoBlocks(t *testing.T) {
+	collector := NewEthBlobs(nil, nil)
+	ch := make(chan prometheus.Metric, 5)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+}
+
+func TestEthBlobsCollect(t *testing.T) {
+	// The node is not queried with a configured schedule.
+	collector := NewEthBlobs(nil, []BlobFork{{Timestamp: 0, UpdateFraction: blobBaseFeeUpdateFraction}})
+	ctx := context.Background()
+
+	for _, block := range []*follower.Block{
+		newBlobBlock(1, 3, 0),
+		newBlobBlock(2, 6, 10*blobBaseFeeUpdateFraction),
+		// Already processed blocks are ignored.
+		newBlobBlock(2, 6, 10*blobBaseFeeUpdateFraction),
+		newBlobBlock(1, 3, 0),
+	} {
+		if err := collector.HandleBlock(ctx, block); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	ch := make(chan prometheus.Metric, 5)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 5 {
+		t.Fatalf("got %v, want 5", got)
+	}
+
+	var metric dto.Metric
+	if err := (<-ch).Write(&metric); err != nil {
+		t.Fatalf("expected metric, got %#v", err)
+	}
+	if got := metric.Histogram.GetSampleCount(); got != 2 {
+		t.Fatalf("got %v samples, want 2", got)
+	}
+	if got := metric.Histogram.GetSampleSum(); got != 9 {
+		t.Fatalf("got %v sum, want 9", got)
+	}
+
+	// e ** 10 is about 22026.
+	for _, want := range []float64{6 * gasPerBlob, 10 * blobBaseFeeUpdateFraction, 6, 22026} {
+		if err := (<-ch).Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		if got := *metric.Gauge.Value; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEthBlobsCollectSchedule(t *testing.T) {
+	collector := NewEthBlobs(nil, []BlobFork{
+		{Timestamp: 200, UpdateFraction: 11684671},
+		{Timestamp: 100, UpdateFraction: 8346193},
+	})
+
+	for _, test := range []struct {
+		timestamp uint64
+		excess    uint64
+		want      float64
+	}{
+		// Blocks before the first fork have no known fee.
+		{50, 10 * 8346193, -1},
+		{100, 10 * 8346193, 22026},
+		{200, 10 * 11684671, 22026},
+	} {
+		block := newBlobBlock(test.timestamp, 1, test.excess)
+		block.Timestamp = hexutil.Uint64(test.timestamp)
+		if err := collector.HandleBlock(context.Background(), block); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+		if got := blobBaseFee(t, collector); got != test.want {
+			t.Fatalf("got %v, want %v at %v", got, test.want, test.timestamp)
+		}
+	}
+}
+
+func TestEthBlobsCollectPrague(t *testing.T) {
+	// Nodes not serving eth_config fall back to the Prague schedule.
+	rpcServer := newBFTServer(t, "eth_chainId", `"0x1"`)
+	defer rpcServer.Close()
+
+	client, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	collector := NewEthBlobs(func() *rpc.Client { return client }, nil)
+
+	block := newBlobBlock(1, 9, 10*pragueBlobBaseFeeUpdateFraction)
+	block.RequestsHash = &common.Hash{}
+	if err := collector.HandleBlock(context.Background(), block); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if got := blobBaseFee(t, collector); got != 22026 {
+		t.Fatalf("got %v, want 22026", got)
+	}
+}
+
+func TestEthBlobsCollectConfig(t *testing.T) {
+	// The node serves the BPO1 fork configuration until BPO2 activates at
+	// 200, and the BPO2 one afterwards.
+	configs := []string{
+		`{"current": {"activationTime": 100, "blobSchedule": {"baseFeeUpdateFraction": 8346193}}, "next": {"activationTime": 200, "blobSchedule": {"baseFeeUpdateFraction": 11684671}}}`,
+		`{"current": {"activationTime": 200, "blobSchedule": {"baseFeeUpdateFraction": 11684671}}, "next": null}`,
+	}
+	calls := 0
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+		if request.Method != "eth_config" {
+			t.Fatalf("unexpected method %v", request.Method)
+		}
+
+		response := `{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, "result": ` + configs[calls] + `}`
+		calls++
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	client, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	collector := NewEthBlobs(func() *rpc.Client { return client }, nil)
+
+	for i, test := range []struct {
+		timestamp uint64
+		excess    uint64
+		want      float64
+		calls     int
+	}{
+		{100, 10 * 8346193, 22026, 1},
+		{150, 10 * 8346193, 22026, 1},
+		{200, 10 * 11684671, 22026, 2},
+		{250, 10 * 11684671, 22026, 2},
+	} {
+		block := newBlobBlock(uint64(i+1), 1, test.excess)
+		block.Timestamp = hexutil.Uint64(test.timestamp)
+		if err := collector.HandleBlock(context.Background(), block); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+		if got := blobBaseFee(t, collector); got != test.want {
+			t.Fatalf("got %v, want %v at %v", got, test.want, test.timestamp)
+		}
+		if calls != test.calls {
+			t.Fatalf("got %v eth_config calls, want %v at %v", calls, test.calls, test.timestamp)
+		}
+	}
+}
+
+func TestEthBlobsCollectConfigError(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct{ ID json.RawMessage }
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+		response := `{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, "error": {"code": -32000, "message": "unavailable"}}`
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	client, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	collector := NewEthBlobs(func() *rpc.Client { return client }, nil)
+
+	// The block is still handled, but its base fee is unknown.
+	if err := collector.HandleBlock(context.Background(), newBlobBlock(1, 3, 0)); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := blobBaseFee(t, collector); got != -1 {
+		t.Fatalf("got %v, want no base fee", got)
+	}
+}
+
+func TestFakeExponential(t *testing.T) {
+	for _, test := range []struct {
+		factor, numerator, denominator, want int64
+	}{
+		{1, 0, 1, 1},
+		{38493, 0, 1000, 38493},
+		{0, 1234, 2345, 0},
+		{1, 2, 1, 6},
+		{2, 5, 2, 23},
+		{1, 50000000, 2225652, 5709098764},
+	} {
+		got := fakeExponential(big.NewInt(test.factor), big.NewInt(test.numerator), big.NewInt(test.denominator))
+		if got.Int64() != test.want {
+			t.Fatalf("fakeExponential(%d, %d, %d) = %v, want %v", test.factor, test.numerator, test.denominator, got, test.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package collector
 
 import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,7 +16,26 @@ type EthBlockTimestamp struct {
 }
 
 type blockResult struct {
-	Timestamp hexutil.Uint64
+	Number     hexutil.Uint64
+	Hash       common.Hash
+	Timestamp  hexutil.Uint64
+	Difficulty hexutil.Big
+}
+
+var errBlockNotFound = errors.New("block not found")
+
+// getBlockByNumber returns the header fields of the block at number, which
+// is either a hex encoded number or a tag such as "latest".
+func getBlockByNumber(ctx context.Context, rpc *rpc.Client, number string) (*blockResult, error) {
+	var result *blockResult
+	if err := rpc.CallContext(ctx, &result, "eth_getBlockByNumber", number, false); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errBlockNotFound
+	}
+
+	return result, nil
 }
 
 func NewEthBlockTimestamp(rpc *rpc.Client) *EthBlockTimestamp {
@@ -32,9 +55,8 @@ func (collector *EthBlockTimestamp) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (collector *EthBlockTimestamp) Collect(ch chan<- prometheus.Metric) {
-	var result *blockResult
-
-	if err := collector.rpc.Call(&result, "eth_getBlockByNumber", "latest", false); err != nil {
+	result, err := getBlockByNumber(context.Background(), collector.rpc, "latest")
+	if err != nil {
 		ch <- prometheus.NewInvalidMetric(collector.desc, err)
 		return
 	}
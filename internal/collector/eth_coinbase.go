@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type EthCoinbase struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewEthCoinbase(rpc *rpc.Client) *EthCoinbase {
+	return &EthCoinbase{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"eth_coinbase_info",
+			"address that receives the mining rewards of this node",
+			[]string{"address"},
+			nil,
+		),
+	}
+}
+
+func (collector *EthCoinbase) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *EthCoinbase) Collect(ch chan<- prometheus.Metric) {
+	var result common.Address
+	if err := collector.rpc.Call(&result, "eth_coinbase"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, 1, result.Hex())
+}
@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type EthDifficulty struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewEthDifficulty(rpc *rpc.Client) *EthDifficulty {
+	return &EthDifficulty{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"eth_difficulty",
+			"proof-of-work difficulty of the most recent block",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *EthDifficulty) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *EthDifficulty) Collect(ch chan<- prometheus.Metric) {
+	result, err := getBlockByNumber(context.Background(), collector.rpc, "latest")
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	value, _ := new(big.Float).SetInt(result.Difficulty.ToInt()).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
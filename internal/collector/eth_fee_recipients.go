@@ -0,0 +1,217 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// otherRecipient labels blocks of fee recipients beyond the series limit.
+const otherRecipient = "other"
+
+// Unnamed fee recipients are ranked by their blocks among the last
+// feeRecipientWindow followed blocks every feeRecipientRankInterval blocks,
+// which is about a day and 20 minutes on mainnet.
+const (
+	feeRecipientWindow       = 7200
+	feeRecipientRankInterval = 100
+)
+
+// EthFeeRecipients attributes followed blocks to their fee recipients.
+// Configured addresses always get their own series, other addresses only
+// while they are among the top limit of the last blocks. Blocks of other
+// addresses are counted as "other", and so are the blocks counted for an
+// address when it drops out of the top, which keeps totals monotonic.
+type EthFeeRecipients struct {
+	names        map[common.Address]string
+	limit        int
+	window       int
+	rankInterval uint64
+	blocksDesc   *prometheus.Desc
+	lastDesc     *prometheus.Desc
+	latestDesc   *prometheus.Desc
+
+	mu      sync.Mutex
+	next    uint64
+	handled uint64
+	blocks  map[string]uint64
+	top     map[common.Address]bool
+	recent  []common.Address
+	oldest  int
+	counts  map[common.Address]int
+	last    map[common.Address]uint64
+	latest  *follower.Block
+}
+
+func NewEthFeeRecipients(names map[common.Address]string, limit int) *EthFeeRecipients {
+	return &EthFeeRecipients{
+		names:        names,
+		limit:        limit,
+		window:       feeRecipientWindow,
+		rankInterval: feeRecipientRankInterval,
+		blocks:       make(map[string]uint64),
+		top:          make(map[common.Address]bool),
+		counts:       make(map[common.Address]int),
+		last:         make(map[common.Address]uint64),
+		blocksDesc: prometheus.NewDesc(
+			"eth_fee_recipient_blocks_total",
+			"number of followed blocks by fee recipient",
+			[]string{"recipient", "name"},
+			nil,
+		),
+		lastDesc: prometheus.NewDesc(
+			"eth_fee_recipient_last_block",
+			"number of the most recent block of a configured fee recipient",
+			[]string{"recipient", "name"},
+			nil,
+		),
+		latestDesc: prometheus.NewDesc(
+			"eth_latest_block_fee_recipient_info",
+			"fee recipient and extra data of the most recent followed block",
+			[]string{"recipient", "name", "extra_data"},
+			nil,
+		),
+	}
+}
+
+func (collector *EthFeeRecipients) HandleBlock(ctx context.Context, block *follower.Block) error {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	number := uint64(block.Number)
+	if number < collector.next {
+		return nil
+	}
+	collector.next = number + 1
+
+	recipient := block.Miner.Hex()
+	if _, ok := collector.names[block.Miner]; ok {
+		collector.last[block.Miner] = number
+	} else {
+		collector.remember(block.Miner)
+
+		// Until the top is full, new addresses join it right away.
+		if !collector.top[block.Miner] && len(collector.top) < collector.limit {
+			collector.top[block.Miner] = true
+		}
+		if !collector.top[block.Miner] {
+			recipient = otherRecipient
+		}
+	}
+
+	collector.blocks[recipient]++
+	collector.latest = block
+
+	collector.handled++
+	if collector.handled%collector.rankInterval == 0 {
+		collector.rank()
+	}
+
+	return nil
+}
+
+// remember adds the block of an unnamed recipient to the window of the
+// last blocks.
+func (collector *EthFeeRecipients) remember(recipient common.Address) {
+	if len(collector.recent) < collector.window {
+		collector.recent = append(collector.recent, recipient)
+		collector.counts[recipient]++
+		return
+	}
+
+	evicted := collector.recent[collector.oldest]
+	if collector.counts[evicted]--; collector.counts[evicted] == 0 {
+		delete(collector.counts, evicted)
+	}
+
+	collector.recent[collector.oldest] = recipient
+	collector.oldest = (collector.oldest + 1) % collector.window
+	collector.counts[recipient]++
+}
+
+// rank replaces the top with the unnamed recipients of most blocks in the
+// window. Ties are broken in favor of the current top to avoid churn.
+func (collector *EthFeeRecipients) rank() {
+	recipients := make([]common.Address, 0, len(collector.counts))
+	for recipient := range collector.counts {
+		recipients = append(recipients, recipient)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		a, b := recipients[i], recipients[j]
+		if collector.counts[a] != collector.counts[b] {
+			return collector.counts[a] > collector.counts[b]
+		}
+		if collector.top[a] != collector.top[b] {
+			return collector.top[a]
+		}
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	if len(recipients) > collector.limit {
+		recipients = recipients[:collector.limit]
+	}
+
+	top := make(map[common.Address]bool, len(recipients))
+	for _, recipient := range recipients {
+		top[recipient] = true
+	}
+
+	for recipient := range collector.top {
+		if top[recipient] {
+			continue
+		}
+		if count, ok := collector.blocks[recipient.Hex()]; ok {
+			collector.blocks[otherRecipient] += count
+			delete(collector.blocks, recipient.Hex())
+		}
+	}
+	collector.top = top
+}
+
+func (collector *EthFeeRecipients) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.blocksDesc
+	ch <- collector.lastDesc
+	ch <- collector.latestDesc
+}
+
+func (collector *EthFeeRecipients) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	for recipient, count := range collector.blocks {
+		name := ""
+		if recipient != otherRecipient {
+			name = collector.names[common.HexToAddress(recipient)]
+		}
+		ch <- prometheus.MustNewConstMetric(collector.blocksDesc, prometheus.CounterValue, float64(count), recipient, name)
+	}
+
+	for address, name := range collector.names {
+		if number, ok := collector.last[address]; ok {
+			ch <- prometheus.MustNewConstMetric(collector.lastDesc, prometheus.GaugeValue, float64(number), address.Hex(), name)
+		}
+	}
+
+	if block := collector.latest; block != nil {
+		name := collector.names[block.Miner]
+		extra := formatExtraData(block.ExtraData)
+		ch <- prometheus.MustNewConstMetric(collector.latestDesc, prometheus.GaugeValue, 1, block.Miner.Hex(), name, extra)
+	}
+}
+
+// formatExtraData returns extra data as text when it is printable, which is
+// how builders usually identify themselves, and hex encoded otherwise.
+func formatExtraData(data []byte) string {
+	if utf8.Valid(data) && strings.IndexFunc(string(data), func(r rune) bool { return !unicode.IsPrint(r) }) < 0 {
+		return string(data)
+	}
+	return hexutil.Encode(data)
+}
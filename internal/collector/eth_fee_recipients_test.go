@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func collectEthFeeRecipients(t *testing.T, collector *EthFeeRecipients) map[string]float64 {
+	ch := make(chan prometheus.Metric, 16)
+
+	collector.Collect(ch)
+	close(ch)
+
+	values := make(map[string]float64)
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+
+		name := result.Desc().String()
+		for _, label := range metric.Label {
+			name += "," + label.GetName() + "=" + label.GetValue()
+		}
+		if metric.Counter != nil {
+			values[name] = *metric.Counter.Value
+		} else {
+			values[name] = *metric.Gauge.Value
+		}
+	}
+	return values
+}
+
+func TestEthFeeRecipientsCollect(t *testing.T) {
+	titan := common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97")
+	a := common.HexToAddress("0x000000000000000000000000000000000000000a")
+	b := common.HexToAddress("0x000000000000000000000000000000000000000b")
+
+	collector := NewEthFeeRecipients(map[common.Address]string{titan: "Titan"}, 1)
+
+	for i, block := range []*follower.Block{
+		{Miner: titan, ExtraData: []byte("Titan (titanbuilder.xyz)")},
+		{Miner: a},
+		{Miner: b},
+		{Miner: titan},
+		{Miner: a},
+		{Miner: b, ExtraData: hexutil.MustDecode("0xd883010d0e846765746888676f312e32312e36856c696e7578")},
+	} {
+		block.Number = hexutil.Uint64(100 + i)
+		if err := collector.HandleBlock(context.Background(), block); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	// Already processed blocks are ignored.
+	if err := collector.HandleBlock(context.Background(), &follower.Block{Number: 100, Miner: a}); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	values := collectEthFeeRecipients(t, collector)
+	extra := "0xd883010d0e846765746888676f312e32312e36856c696e7578"
+	want := map[string]float64{
+		collector.blocksDesc.String() + ",name=Titan,recipient=" + titan.Hex():                 2,
+		collector.blocksDesc.String() + ",name=,recipient=" + a.Hex():                          2,
+		collector.blocksDesc.String() + ",name=,recipient=other":                               2,
+		collector.lastDesc.String() + ",name=Titan,recipient=" + titan.Hex():                   103,
+		collector.latestDesc.String() + ",extra_data=" + extra + ",name=,recipient=" + b.Hex(): 1,
+	}
+
+	if len(values) != len(want) {
+		t.Fatalf("got %v, want %v", values, want)
+	}
+	for name, value := range want {
+		if got, ok := values[name]; !ok || got != value {
+			t.Fatalf("got %v for %v, want %v", got, name, value)
+		}
+	}
+}
+
+func TestEthFeeRecipientsRank(t *testing.T) {
+	a := common.HexToAddress("0x000000000000000000000000000000000000000a")
+	b := common.HexToAddress("0x000000000000000000000000000000000000000b")
+
+	collector := NewEthFeeRecipients(nil, 1)
+	collector.window = 4
+	collector.rankInterval = 4
+
+	handle := func(first uint64, miners ...common.Address) {
+		for i, miner := range miners {
+			block := &follower.Block{Number: hexutil.Uint64(first + uint64(i)), Miner: miner}
+			if err := collector.HandleBlock(context.Background(), block); err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+		}
+	}
+	blocks := func(recipient string) float64 {
+		return collectEthFeeRecipients(t, collector)[collector.blocksDesc.String()+",name=,recipient="+recipient]
+	}
+
+	// The first address seen joins the top, but b replaces it once ranked,
+	// and the blocks counted for a move to other.
+	handle(1, a, b, b, b)
+	if got := blocks(a.Hex()); got != 0 {
+		t.Fatalf("got %v blocks of a, want 0", got)
+	}
+	if got := blocks(otherRecipient); got != 4 {
+		t.Fatalf("got %v other blocks, want 4", got)
+	}
+
+	handle(5, b)
+	if got := blocks(b.Hex()); got != 1 {
+		t.Fatalf("got %v blocks of b, want 1", got)
+	}
+
+	// Blocks of b leave the window as a takes over.
+	handle(6, a, a, a)
+	values := collectEthFeeRecipients(t, collector)
+	if got := values[collector.blocksDesc.String()+",name=,recipient=other"]; got != 8 || len(values) != 2 {
+		t.Fatalf("got %v, want 8 other blocks only", values)
+	}
+
+	handle(9, a)
+	if got := blocks(a.Hex()); got != 1 {
+		t.Fatalf("got %v blocks of a, want 1", got)
+	}
+}
+
+func TestFormatExtraData(t *testing.T) {
+	if got := formatExtraData([]byte("beaverbuild.org")); got != "beaverbuild.org" {
+		t.Fatalf("got %v, want beaverbuild.org", got)
+	}
+	if got := formatExtraData([]byte{0xd8, 0x83, 0x01}); got != "0xd88301" {
+		t.Fatalf("got %v, want 0xd88301", got)
+	}
+}
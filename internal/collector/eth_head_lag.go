@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var errReferencePending = errors.New("reference head not queried yet")
+
+// ReferenceHead caches the most recent block of a reference endpoint, or the
+// error querying it, so that it is queried at most once per interval. Only a
+// single query runs at a time, and callers get the cached result meanwhile, so
+// that a slow reference does not hold up scrapes.
+type ReferenceHead struct {
+	rpc      *rpc.Client
+	interval time.Duration
+	timeout  time.Duration
+
+	mu         sync.Mutex
+	updatedAt  time.Time
+	refreshing bool
+	head       *blockResult
+	err        error
+}
+
+func NewReferenceHead(rpc *rpc.Client, interval, timeout time.Duration) *ReferenceHead {
+	return &ReferenceHead{
+		rpc:      rpc,
+		interval: interval,
+		timeout:  timeout,
+		err:      errReferencePending,
+	}
+}
+
+func (ref *ReferenceHead) get(ctx context.Context) (*blockResult, error) {
+	ref.mu.Lock()
+	if ref.refreshing || (!ref.updatedAt.IsZero() && time.Since(ref.updatedAt) < ref.interval) {
+		defer ref.mu.Unlock()
+		return ref.head, ref.err
+	}
+	ref.refreshing = true
+	ref.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, ref.timeout)
+	defer cancel()
+	head, err := getBlockByNumber(ctx, ref.rpc, "latest")
+
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	ref.head, ref.err = head, err
+	ref.updatedAt = time.Now()
+	ref.refreshing = false
+
+	return ref.head, ref.err
+}
+
+type EthHeadLag struct {
+	rpc         *rpc.Client
+	reference   *ReferenceHead
+	blocksDesc  *prometheus.Desc
+	secondsDesc *prometheus.Desc
+	matchDesc   *prometheus.Desc
+}
+
+func NewEthHeadLag(rpc *rpc.Client, reference *ReferenceHead) *EthHeadLag {
+	return &EthHeadLag{
+		rpc:       rpc,
+		reference: reference,
+		blocksDesc: prometheus.NewDesc(
+			"eth_head_lag_blocks",
+			"number of blocks the node is behind the reference",
+			nil,
+			nil,
+		),
+		secondsDesc: prometheus.NewDesc(
+			"eth_head_lag_seconds",
+			"seconds the most recent block of the node is behind the reference",
+			nil,
+			nil,
+		),
+		matchDesc: prometheus.NewDesc(
+			"eth_head_hash_match",
+			"whether the node has the same block hash as the reference at the reference height",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *EthHeadLag) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.blocksDesc
+	ch <- collector.secondsDesc
+	ch <- collector.matchDesc
+}
+
+func (collector *EthHeadLag) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	ref, err := collector.reference.get(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.blocksDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.secondsDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.matchDesc, err)
+		return
+	}
+
+	head, err := getBlockByNumber(ctx, collector.rpc, "latest")
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.blocksDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.secondsDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.matchDesc, err)
+		return
+	}
+
+	// The reference is cached, so the node may be ahead of it.
+	value := 0.0
+	if ref.Number > head.Number {
+		value = float64(ref.Number - head.Number)
+	}
+	ch <- prometheus.MustNewConstMetric(collector.blocksDesc, prometheus.GaugeValue, value)
+
+	value = 0.0
+	if ref.Timestamp > head.Timestamp {
+		value = float64(ref.Timestamp - head.Timestamp)
+	}
+	ch <- prometheus.MustNewConstMetric(collector.secondsDesc, prometheus.GaugeValue, value)
+
+	// The hash can only be compared once the node reaches the reference height.
+	if head.Number < ref.Number {
+		return
+	}
+
+	block := head
+	if head.Number > ref.Number {
+		block, err = getBlockByNumber(ctx, collector.rpc, hexutil.EncodeUint64(uint64(ref.Number)))
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(collector.matchDesc, err)
+			return
+		}
+	}
+
+	value = 0.0
+	if block.Hash == ref.Hash {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(collector.matchDesc, prometheus.GaugeValue, value)
+}
@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func collectEthHeadLag(t *testing.T, collector *EthHeadLag) []dto.Metric {
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	var metrics []dto.Metric
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+func TestEthHeadLagCollectError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewEthHeadLag(rpc, NewReferenceHead(rpc, time.Minute, time.Second))
+	ch := make(chan prometheus.Metric, 3)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestReferenceHeadCachesErrors(t *testing.T) {
+	calls := 0
+	reference := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer reference.Close()
+
+	rpc, err := rpc.DialHTTP(reference.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	// A failing reference is not queried again on every scrape.
+	head := NewReferenceHead(rpc, time.Minute, time.Second)
+	for i := 0; i < 3; i++ {
+		if _, err := head.get(context.Background()); err == nil {
+			t.Fatalf("expected error")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("got %v calls, want 1", calls)
+	}
+}
+
+func TestReferenceHeadTimeout(t *testing.T) {
+	queried := make(chan struct{})
+	done := make(chan struct{})
+	reference := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(queried)
+		<-done
+	}))
+	defer reference.Close()
+	defer close(done)
+
+	rpc, err := rpc.DialHTTP(reference.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	head := NewReferenceHead(rpc, time.Minute, 100*time.Millisecond)
+	result := make(chan error)
+	go func() {
+		_, err := head.get(context.Background())
+		result <- err
+	}()
+
+	// Other callers do not wait for the hung query.
+	<-queried
+	if _, err := head.get(context.Background()); err != errReferencePending {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if err := <-result; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestEthHeadLagCollectBehind(t *testing.T) {
+	reference := newChainServer(t, hash0, hash1, hash2)
+	defer reference.Close()
+	node := newChainServer(t, hash0)
+	defer node.Close()
+
+	collector := NewEthHeadLag(dialNode(t, "node", node.URL).RPC,
+		NewReferenceHead(dialNode(t, "reference", reference.URL).RPC, time.Minute, time.Second))
+
+	metrics := collectEthHeadLag(t, collector)
+	if got := len(metrics); got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+	if got := *metrics[0].Gauge.Value; got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+	if got := *metrics[1].Gauge.Value; got != 24 {
+		t.Fatalf("got %v, want 24", got)
+	}
+}
+
+func TestEthHeadLagCollectHashMatch(t *testing.T) {
+	reference := newChainServer(t, hash0, hash1)
+	defer reference.Close()
+	node := newChainServer(t, hash0, hash1, hash2)
+	defer node.Close()
+	forked := newChainServer(t, hash0, hashF, hash2)
+	defer forked.Close()
+
+	ref := NewReferenceHead(dialNode(t, "reference", reference.URL).RPC, time.Minute, time.Second)
+
+	metrics := collectEthHeadLag(t, NewEthHeadLag(dialNode(t, "node", node.URL).RPC, ref))
+	if got := len(metrics); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+	if got := *metrics[0].Gauge.Value; got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+	if got := *metrics[2].Gauge.Value; got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	// The reference head is cached, so it is not queried again.
+	reference.Close()
+
+	metrics = collectEthHeadLag(t, NewEthHeadLag(dialNode(t, "forked", forked.URL).RPC, ref))
+	if got := len(metrics); got != 3 {
+		t.Fatalf("got %v, want 3", got)
+	}
+	if got := *metrics[2].Gauge.Value; got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+}
@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// txTypes maps EIP-2718 transaction types to label values.
+var txTypes = []string{
+	0x00: "legacy",
+	0x01: "access_list",
+	0x02: "dynamic_fee",
+	0x03: "blob",
+	0x04: "set_code",
+}
+
+// codeBatchSize bounds the number of eth_getCode calls sent in one batch.
+const codeBatchSize = 100
+
+// EthLatestBlockContents breaks down the transactions of the latest block.
+type EthLatestBlockContents struct {
+	rpc           *rpc.Client
+	typesDesc     *prometheus.Desc
+	creationsDesc *prometheus.Desc
+	transfersDesc *prometheus.Desc
+	callsDesc     *prometheus.Desc
+	valueDesc     *prometheus.Desc
+}
+
+type fullBlockResult struct {
+	Number       hexutil.Uint64
+	Transactions []transactionResult
+}
+
+type transactionResult struct {
+	Type  hexutil.Uint64
+	To    *common.Address
+	Value hexutil.Big
+}
+
+// typeName returns the label value of the transaction type.
+func (tx *transactionResult) typeName() string {
+	if int(tx.Type) < len(txTypes) {
+		return txTypes[tx.Type]
+	}
+	return "unknown"
+}
+
+func NewEthLatestBlockContents(rpc *rpc.Client) *EthLatestBlockContents {
+	return &EthLatestBlockContents{
+		rpc: rpc,
+		typesDesc: prometheus.NewDesc(
+			"eth_latest_block_transactions_by_type",
+			"number of transactions in the latest block by transaction type",
+			[]string{"type"},
+			nil,
+		),
+		creationsDesc: prometheus.NewDesc(
+			"eth_latest_block_contract_creations",
+			"number of contract creations in the latest block",
+			nil,
+			nil,
+		),
+		transfersDesc: prometheus.NewDesc(
+			"eth_latest_block_transfers",
+			"number of transactions to accounts without code in the latest block",
+			nil,
+			nil,
+		),
+		callsDesc: prometheus.NewDesc(
+			"eth_latest_block_contract_calls",
+			"number of transactions to accounts with code in the latest block",
+			nil,
+			nil,
+		),
+		valueDesc: prometheus.NewDesc(
+			"eth_latest_block_value_transferred",
+			"total value transferred by transactions in the latest block in wei",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *EthLatestBlockContents) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.typesDesc
+	ch <- collector.creationsDesc
+	ch <- collector.transfersDesc
+	ch <- collector.callsDesc
+	ch <- collector.valueDesc
+}
+
+func (collector *EthLatestBlockContents) Collect(ch chan<- prometheus.Metric) {
+	var block *fullBlockResult
+	err := collector.rpc.CallContext(context.Background(), &block, "eth_getBlockByNumber", "latest", true)
+	if err == nil && block == nil {
+		err = errBlockNotFound
+	}
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.typesDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.creationsDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.transfersDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.callsDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.valueDesc, err)
+		return
+	}
+
+	// Recipients are told apart by their code rather than by the input of
+	// transactions, which may carry data to accounts without code and none
+	// to contracts.
+	codes, codesErr := collector.getCodes(context.Background(), block)
+
+	types := make(map[string]int)
+	var (
+		creations, transfers, calls int
+		value                       = new(big.Int)
+	)
+	for _, tx := range block.Transactions {
+		types[tx.typeName()]++
+
+		switch {
+		case tx.To == nil:
+			creations++
+		case codes[*tx.To]:
+			calls++
+		default:
+			transfers++
+		}
+
+		value.Add(value, tx.Value.ToInt())
+	}
+
+	for _, name := range append(txTypes, "unknown") {
+		ch <- prometheus.MustNewConstMetric(collector.typesDesc, prometheus.GaugeValue, float64(types[name]), name)
+	}
+	ch <- prometheus.MustNewConstMetric(collector.creationsDesc, prometheus.GaugeValue, float64(creations))
+	if codesErr != nil {
+		ch <- prometheus.NewInvalidMetric(collector.transfersDesc, codesErr)
+		ch <- prometheus.NewInvalidMetric(collector.callsDesc, codesErr)
+	} else {
+		ch <- prometheus.MustNewConstMetric(collector.transfersDesc, prometheus.GaugeValue, float64(transfers))
+		ch <- prometheus.MustNewConstMetric(collector.callsDesc, prometheus.GaugeValue, float64(calls))
+	}
+
+	total, _ := new(big.Float).SetInt(value).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.valueDesc, prometheus.GaugeValue, total)
+}
+
+// getCodes returns whether the recipients of the transactions of block have
+// code at that block.
+func (collector *EthLatestBlockContents) getCodes(ctx context.Context, block *fullBlockResult) (map[common.Address]bool, error) {
+	var recipients []common.Address
+	codes := make(map[common.Address]bool)
+	for _, tx := range block.Transactions {
+		if tx.To == nil {
+			continue
+		}
+		if _, ok := codes[*tx.To]; !ok {
+			codes[*tx.To] = false
+			recipients = append(recipients, *tx.To)
+		}
+	}
+
+	number := hexutil.EncodeUint64(uint64(block.Number))
+	for start := 0; start < len(recipients); start += codeBatchSize {
+		end := start + codeBatchSize
+		if end > len(recipients) {
+			end = len(recipients)
+		}
+
+		batch := make([]rpc.BatchElem, end-start)
+		results := make([]hexutil.Bytes, end-start)
+		for i, recipient := range recipients[start:end] {
+			batch[i] = rpc.BatchElem{Method: "eth_getCode", Args: []interface{}{recipient, number}, Result: &results[i]}
+		}
+		if err := collector.rpc.BatchCallContext(ctx, batch); err != nil {
+			return nil, err
+		}
+
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nil, elem.Error
+			}
+			codes[recipients[start+i]] = len(results[i]) > 0
+		}
+	}
+
+	return codes, nil
+}
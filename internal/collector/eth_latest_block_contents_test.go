@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestEthLatestBlockContentsCollectError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewEthLatestBlockContents(rpc)
+	ch := make(chan prometheus.Metric, 5)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 5 {
+		t.Fatalf("got %v, want 5", got)
+	}
+
+	var metric dto.Metric
+	for result := range ch {
+		err := result.Write(&metric)
+		if err == nil {
+			t.Fatalf("expected invalid metric, got %#v", metric)
+		}
+		if _, ok := err.(*url.Error); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+// newBlockContentsServer serves the latest block and the code of its
+// recipients, of which only 0xaa is a contract. With codeErr, eth_getCode
+// fails.
+func newBlockContentsServer(t *testing.T, codeErr bool) *httptest.Server {
+	block := `{"number": "0x10", "transactions": [
+		{"type": "0x0", "to": "0x00000000000000000000000000000000000000aa", "input": "0x", "value": "0xde0b6b3a7640000"},
+		{"type": "0x2", "to": "0x00000000000000000000000000000000000000aa", "input": "0xa9059cbb", "value": "0x0"},
+		{"type": "0x2", "to": null, "input": "0x6080", "value": "0x0"},
+		{"type": "0x3", "to": "0x00000000000000000000000000000000000000aa", "input": "0x", "value": "0x1"},
+		{"type": "0x7e", "to": "0x00000000000000000000000000000000000000bb", "input": "0x01", "value": "0x0"}
+	]}`
+
+	type request struct {
+		ID     json.RawMessage
+		Method string
+		Params []json.RawMessage
+	}
+	respond := func(request request) string {
+		switch request.Method {
+		case "eth_getBlockByNumber":
+			return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, request.ID, block)
+		case "eth_getCode":
+			if codeErr {
+				return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32000, "message": "missing trie node"}}`, request.ID)
+			}
+			if string(request.Params[1]) != `"0x10"` {
+				t.Fatalf("got code at %s, want 0x10", request.Params[1])
+			}
+			code := "0x"
+			if common.HexToAddress(strings.Trim(string(request.Params[0]), `"`)) == common.HexToAddress("0xaa") {
+				code = "0x6080"
+			}
+			return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": "%s"}`, request.ID, code)
+		}
+		return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32601, "message": "method not found"}}`, request.ID)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("could not read a request: %#v", err)
+		}
+
+		var response string
+		if bytes.HasPrefix(body, []byte("[")) {
+			var requests []request
+			if err := json.Unmarshal(body, &requests); err != nil {
+				t.Fatalf("could not decode a batch request: %#v", err)
+			}
+			var responses []string
+			for _, request := range requests {
+				responses = append(responses, respond(request))
+			}
+			response = "[" + strings.Join(responses, ",") + "]"
+		} else {
+			var request request
+			if err := json.Unmarshal(body, &request); err != nil {
+				t.Fatalf("could not decode a request: %#v", err)
+			}
+			response = respond(request)
+		}
+
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func TestEthLatestBlockContentsCollect(t *testing.T) {
+	rpcServer := newBlockContentsServer(t, false)
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewEthLatestBlockContents(rpc)
+	ch := make(chan prometheus.Metric, 16)
+
+	collector.Collect(ch)
+	close(ch)
+
+	types := make(map[string]float64)
+	values := make(map[string]float64)
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+
+		switch result.Desc() {
+		case collector.typesDesc:
+			types[metric.Label[0].GetValue()] = *metric.Gauge.Value
+		default:
+			values[result.Desc().String()] = *metric.Gauge.Value
+		}
+	}
+
+	wantTypes := map[string]float64{"legacy": 1, "access_list": 0, "dynamic_fee": 2, "blob": 1, "set_code": 0, "unknown": 1}
+	for name, want := range wantTypes {
+		if got := types[name]; got != want {
+			t.Fatalf("got %v %v transactions, want %v", got, name, want)
+		}
+	}
+
+	// Transactions to the contract are calls with or without input, and
+	// the one to the account without code is a transfer despite its input.
+	wantValues := map[*prometheus.Desc]float64{
+		collector.creationsDesc: 1,
+		collector.transfersDesc: 1,
+		collector.callsDesc:     3,
+		collector.valueDesc:     1e18 + 1,
+	}
+	for desc, want := range wantValues {
+		if got := values[desc.String()]; got != want {
+			t.Fatalf("got %v for %v, want %v", got, desc, want)
+		}
+	}
+}
+
+func TestEthLatestBlockContentsCollectCodeError(t *testing.T) {
+	rpcServer := newBlockContentsServer(t, true)
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewEthLatestBlockContents(rpc)
+	ch := make(chan prometheus.Metric, 16)
+
+	collector.Collect(ch)
+	close(ch)
+
+	invalid := 0
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			if result.Desc() != collector.transfersDesc && result.Desc() != collector.callsDesc {
+				t.Fatalf("unexpected error %#v for %v", err, result.Desc())
+			}
+			invalid++
+		}
+	}
+	if invalid != 2 {
+		t.Fatalf("got %v invalid metrics, want 2", invalid)
+	}
+}
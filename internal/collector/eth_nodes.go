@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Node is a named JSON-RPC endpoint of a fleet.
+type Node struct {
+	Name string
+	RPC  *rpc.Client
+}
+
+type EthNodes struct {
+	nodes        []Node
+	timeout      time.Duration
+	numberDesc   *prometheus.Desc
+	infoDesc     *prometheus.Desc
+	lagDesc      *prometheus.Desc
+	mismatchDesc *prometheus.Desc
+}
+
+type nodeHead struct {
+	head *blockResult
+	err  error
+}
+
+func NewEthNodes(nodes []Node, timeout time.Duration) *EthNodes {
+	return &EthNodes{
+		nodes:   nodes,
+		timeout: timeout,
+		numberDesc: prometheus.NewDesc(
+			"eth_node_head_block_number",
+			"number of the most recent block of the node",
+			[]string{"fleet_node"},
+			nil,
+		),
+		infoDesc: prometheus.NewDesc(
+			"eth_node_head_info",
+			"hash of the most recent block of the node",
+			[]string{"fleet_node", "hash"},
+			nil,
+		),
+		lagDesc: prometheus.NewDesc(
+			"eth_node_head_lag_blocks",
+			"number of blocks the node is behind the highest head of the fleet",
+			[]string{"fleet_node"},
+			nil,
+		),
+		mismatchDesc: prometheus.NewDesc(
+			"eth_nodes_hash_mismatch",
+			"whether nodes disagree on the block hash at their highest common height",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *EthNodes) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.numberDesc
+	ch <- collector.infoDesc
+	ch <- collector.lagDesc
+	ch <- collector.mismatchDesc
+}
+
+func (collector *EthNodes) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collector.timeout)
+	defer cancel()
+
+	heads := collector.getBlocks(ctx, func(int) string { return "latest" })
+
+	var (
+		responding int
+		highest    uint64
+		lowest     uint64
+	)
+	for i, result := range heads {
+		name := collector.nodes[i].Name
+		if result.err != nil {
+			err := fmt.Errorf("%s: %w", name, result.err)
+			ch <- prometheus.NewInvalidMetric(collector.numberDesc, err)
+			continue
+		}
+
+		number := uint64(result.head.Number)
+		if responding == 0 || number > highest {
+			highest = number
+		}
+		if responding == 0 || number < lowest {
+			lowest = number
+		}
+		responding++
+
+		ch <- prometheus.MustNewConstMetric(collector.numberDesc, prometheus.GaugeValue, float64(number), name)
+		// A single series per node is exported as only the head hash is kept.
+		ch <- prometheus.MustNewConstMetric(collector.infoDesc, prometheus.GaugeValue, 1, name, result.head.Hash.Hex())
+	}
+
+	if responding == 0 {
+		return
+	}
+
+	for i, result := range heads {
+		if result.err != nil {
+			continue
+		}
+		lag := float64(highest - uint64(result.head.Number))
+		ch <- prometheus.MustNewConstMetric(collector.lagDesc, prometheus.GaugeValue, lag, collector.nodes[i].Name)
+	}
+
+	if responding < 2 {
+		return
+	}
+
+	// Nodes that are already at the common height do not need another call.
+	blocks := collector.getBlocks(ctx, func(i int) string {
+		if heads[i].err != nil {
+			return ""
+		}
+		if uint64(heads[i].head.Number) == lowest {
+			return ""
+		}
+		return hexutil.EncodeUint64(lowest)
+	})
+
+	mismatch := 0.0
+	first := ""
+	for i, result := range blocks {
+		if heads[i].err != nil {
+			continue
+		}
+
+		block := heads[i].head
+		if uint64(block.Number) != lowest {
+			if result.err != nil {
+				err := fmt.Errorf("%s: %w", collector.nodes[i].Name, result.err)
+				ch <- prometheus.NewInvalidMetric(collector.mismatchDesc, err)
+				return
+			}
+			block = result.head
+		}
+
+		hash := block.Hash.Hex()
+		if first == "" {
+			first = hash
+		} else if hash != first {
+			mismatch = 1
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.mismatchDesc, prometheus.GaugeValue, mismatch)
+}
+
+// getBlocks concurrently fetches the block numbered number(i) from the i-th
+// node. Nodes for which number returns an empty string are skipped.
+func (collector *EthNodes) getBlocks(ctx context.Context, number func(int) string) []nodeHead {
+	results := make([]nodeHead, len(collector.nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range collector.nodes {
+		n := number(i)
+		if n == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, rpc *rpc.Client, n string) {
+			defer wg.Done()
+			results[i].head, results[i].err = getBlockByNumber(ctx, rpc, n)
+		}(i, node.RPC, n)
+	}
+	wg.Wait()
+
+	return results
+}
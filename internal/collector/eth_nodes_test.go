@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// newChainServer serves eth_getBlockByNumber for a chain whose block hashes
+// are given by hashes, the last one being the head. Blocks are 12 seconds
+// apart.
+func newChainServer(t *testing.T, hashes ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Params []interface{}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		number := uint64(len(hashes) - 1)
+		if tag := request.Params[0].(string); tag != "latest" {
+			number = hexutil.MustDecodeUint64(tag)
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {"number": "%s", "hash": "%s", "timestamp": "%s"}}`,
+			request.ID, hexutil.EncodeUint64(number), hashes[number], hexutil.EncodeUint64(number*12))
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func collectEthNodes(t *testing.T, nodes []Node) map[string]float64 {
+	collector := NewEthNodes(nodes, time.Second)
+	ch := make(chan prometheus.Metric, 16)
+
+	collector.Collect(ch)
+	close(ch)
+
+	values := make(map[string]float64)
+	for result := range ch {
+		var metric dto.Metric
+		if err := result.Write(&metric); err != nil {
+			values["error"]++
+			continue
+		}
+
+		name := result.Desc().String()
+		for _, label := range metric.Label {
+			name += "," + label.GetName() + "=" + label.GetValue()
+		}
+		values[name] = *metric.Gauge.Value
+	}
+	return values
+}
+
+func dialNode(t *testing.T, name, url string) Node {
+	rpc, err := rpc.DialHTTP(url)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	return Node{Name: name, RPC: rpc}
+}
+
+const (
+	hash0 = "0x0000000000000000000000000000000000000000000000000000000000000000"
+	hash1 = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	hash2 = "0x2222222222222222222222222222222222222222222222222222222222222222"
+	hashF = "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+)
+
+func lookup(t *testing.T, values map[string]float64, desc *prometheus.Desc, labels string) float64 {
+	value, ok := values[desc.String()+labels]
+	if !ok {
+		t.Fatalf("missing %s%s in %v", desc, labels, values)
+	}
+	return value
+}
+
+func TestEthNodesCollect(t *testing.T) {
+	a := newChainServer(t, hash0, hash1, hash2)
+	defer a.Close()
+	b := newChainServer(t, hash0, hash1)
+	defer b.Close()
+
+	collector := NewEthNodes(nil, time.Second)
+	values := collectEthNodes(t, []Node{dialNode(t, "a", a.URL), dialNode(t, "b", b.URL)})
+
+	if got := lookup(t, values, collector.numberDesc, ",fleet_node=a"); got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+	if got := lookup(t, values, collector.numberDesc, ",fleet_node=b"); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+	if got := lookup(t, values, collector.infoDesc, ",fleet_node=a,hash="+hash2); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+	if got := lookup(t, values, collector.infoDesc, ",fleet_node=b,hash="+hash1); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+	if got := lookup(t, values, collector.lagDesc, ",fleet_node=a"); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+	if got := lookup(t, values, collector.lagDesc, ",fleet_node=b"); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+	if got := lookup(t, values, collector.mismatchDesc, ""); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+}
+
+func TestEthNodesCollectHashMismatch(t *testing.T) {
+	a := newChainServer(t, hash0, hash1, hash2)
+	defer a.Close()
+	b := newChainServer(t, hash0, hashF)
+	defer b.Close()
+
+	collector := NewEthNodes(nil, time.Second)
+	values := collectEthNodes(t, []Node{dialNode(t, "a", a.URL), dialNode(t, "b", b.URL)})
+
+	if got := lookup(t, values, collector.mismatchDesc, ""); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+}
+
+func TestEthNodesCollectError(t *testing.T) {
+	a := newChainServer(t, hash0, hash1)
+	defer a.Close()
+
+	collector := NewEthNodes(nil, time.Second)
+	values := collectEthNodes(t, []Node{dialNode(t, "a", a.URL), dialNode(t, "b", "http://localhost")})
+
+	if got := values["error"]; got != 1 {
+		t.Fatalf("got %v errors, want 1", got)
+	}
+	if got := lookup(t, values, collector.lagDesc, ",fleet_node=a"); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+	if _, ok := values[collector.mismatchDesc.String()]; ok {
+		t.Fatalf("expected no mismatch metric with a single responding node")
+	}
+}
+
+func TestGetBlockByNumberNotFound(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": null}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	node := dialNode(t, "a", rpcServer.URL)
+	if _, err := getBlockByNumber(context.Background(), node.RPC, "latest"); err != errBlockNotFound {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
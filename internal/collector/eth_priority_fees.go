@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// priorityFeeBuckets range from 0.001 to 100 gwei.
+var priorityFeeBuckets = []float64{1e6, 1e7, 1e8, 5e8, 1e9, 2e9, 5e9, 1e10, 2e10, 5e10, 1e11}
+
+// EthPriorityFees exports a histogram of the effective priority fees of the
+// transactions of every followed block.
+type EthPriorityFees struct {
+	histogram prometheus.Histogram
+
+	mu   sync.Mutex
+	next uint64
+}
+
+func NewEthPriorityFees() *EthPriorityFees {
+	return &EthPriorityFees{
+		histogram: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "eth_effective_priority_fee",
+			Help:    "effective priority fee per gas of transactions in followed blocks in wei",
+			Buckets: priorityFeeBuckets,
+		}),
+	}
+}
+
+func (collector *EthPriorityFees) HandleBlock(ctx context.Context, block *follower.Block) error {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	number := uint64(block.Number)
+	if number < collector.next {
+		return nil
+	}
+	collector.next = number + 1
+
+	baseFee := new(big.Int)
+	if block.BaseFeePerGas != nil {
+		baseFee = block.BaseFeePerGas.ToInt()
+	}
+
+	for i := range block.Transactions {
+		fee, _ := new(big.Float).SetInt(effectivePriorityFee(&block.Transactions[i], baseFee)).Float64()
+		collector.histogram.Observe(fee)
+	}
+
+	return nil
+}
+
+// effectivePriorityFee returns the tip per gas paid to the fee recipient.
+func effectivePriorityFee(tx *follower.Transaction, baseFee *big.Int) *big.Int {
+	if tx.MaxFeePerGas != nil && tx.MaxPriorityFeePerGas != nil {
+		tip := new(big.Int).Sub(tx.MaxFeePerGas.ToInt(), baseFee)
+		if limit := tx.MaxPriorityFeePerGas.ToInt(); tip.Cmp(limit) > 0 {
+			tip.Set(limit)
+		}
+		return tip
+	}
+
+	if tx.GasPrice != nil {
+		return new(big.Int).Sub(tx.GasPrice.ToInt(), baseFee)
+	}
+	return new(big.Int)
+}
+
+func (collector *EthPriorityFees) Describe(ch chan<- *prometheus.Desc) {
+	collector.histogram.Describe(ch)
+}
+
+func (collector *EthPriorityFees) Collect(ch chan<- prometheus.Metric) {
+	collector.histogram.Collect(ch)
+}
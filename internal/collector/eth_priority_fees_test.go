@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestEthPriorityFeesCollect(t *testing.T) {
+	gwei := func(n int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(n * 1e9)) }
+
+	collector := NewEthPriorityFees()
+	ctx := context.Background()
+
+	blocks := []*follower.Block{
+		{
+			Number:        1,
+			BaseFeePerGas: gwei(1),
+			Transactions: []follower.Transaction{
+				{GasPrice: gwei(2)},
+				{MaxPriorityFeePerGas: gwei(1), MaxFeePerGas: gwei(20)},
+				{MaxPriorityFeePerGas: gwei(1), MaxFeePerGas: (*hexutil.Big)(big.NewInt(1e9 + 1))},
+				// Deposit transactions pay no priority fee.
+				{},
+			},
+		},
+		{
+			Number:        2,
+			BaseFeePerGas: gwei(1),
+			Transactions:  []follower.Transaction{{MaxPriorityFeePerGas: gwei(10), MaxFeePerGas: gwei(20)}},
+		},
+	}
+	// Already processed blocks are ignored.
+	for _, block := range append(blocks, blocks[0]) {
+		if err := collector.HandleBlock(ctx, block); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	var metric dto.Metric
+	if err := (<-ch).Write(&metric); err != nil {
+		t.Fatalf("expected metric, got %#v", err)
+	}
+
+	// Tips are 1 gwei, 1 gwei, 1 wei, 0 and 10 gwei.
+	if got := metric.Histogram.GetSampleCount(); got != 5 {
+		t.Fatalf("got %v samples, want 5", got)
+	}
+	if got := metric.Histogram.GetSampleSum(); got != 12e9+1 {
+		t.Fatalf("got %v sum, want 12000000001", got)
+	}
+	if got := metric.Histogram.Bucket[0].GetCumulativeCount(); got != 2 {
+		t.Fatalf("got %v in the first bucket, want 2", got)
+	}
+}
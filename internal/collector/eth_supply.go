@@ -0,0 +1,256 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	gweiToWei = big.NewInt(1e9)
+
+	errReceiptNotFound  = errors.New("receipt not found")
+	errReceiptsMismatch = errors.New("receipts do not match the block")
+)
+
+// EthSupply accumulates fees burned, priority fees paid to fee recipients and
+// withdrawals of every followed block. Progress is persisted to a state file
+// so that restarts neither double count nor skip blocks.
+type EthSupply struct {
+	client          follower.ClientFunc
+	path            string
+	burnedDesc      *prometheus.Desc
+	tipsDesc        *prometheus.Desc
+	withdrawalsDesc *prometheus.Desc
+	blockDesc       *prometheus.Desc
+
+	mu    sync.Mutex
+	state supplyState
+}
+
+type supplyState struct {
+	Block       *uint64  `json:"block"`
+	Burned      *big.Int `json:"burned"`
+	Tips        *big.Int `json:"tips"`
+	Withdrawals *big.Int `json:"withdrawals"`
+}
+
+type receiptResult struct {
+	BlockHash         common.Hash
+	GasUsed           hexutil.Uint64
+	EffectiveGasPrice *hexutil.Big
+}
+
+// NewEthSupply loads the state file at path, if any. An empty path disables
+// persistence. A corrupt state file is logged and accounting starts afresh.
+func NewEthSupply(client follower.ClientFunc, path string) (*EthSupply, error) {
+	collector := &EthSupply{
+		client: client,
+		path:   path,
+		state: supplyState{
+			Burned:      new(big.Int),
+			Tips:        new(big.Int),
+			Withdrawals: new(big.Int),
+		},
+		burnedDesc: prometheus.NewDesc(
+			"eth_burned_fees_wei_total",
+			"total base fees burned in wei",
+			nil,
+			nil,
+		),
+		tipsDesc: prometheus.NewDesc(
+			"eth_priority_fees_wei_total",
+			"total priority fees paid to fee recipients in wei",
+			nil,
+			nil,
+		),
+		withdrawalsDesc: prometheus.NewDesc(
+			"eth_withdrawals_wei_total",
+			"total amount withdrawn from the consensus layer in wei",
+			nil,
+			nil,
+		),
+		blockDesc: prometheus.NewDesc(
+			"eth_supply_processed_block",
+			"number of the most recent block accounted for in supply metrics",
+			nil,
+			nil,
+		),
+	}
+
+	if path == "" {
+		return collector, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return collector, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state supplyState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("ignoring the supply state file %v: %v", path, err)
+		return collector, nil
+	}
+	if state.Block == nil || state.Burned == nil || state.Tips == nil || state.Withdrawals == nil {
+		log.Printf("ignoring the supply state file %v: missing fields", path)
+		return collector, nil
+	}
+	collector.state = state
+
+	return collector, nil
+}
+
+// Next returns the number of the first block that has not been processed
+// yet and whether there is any progress at all.
+func (collector *EthSupply) Next() (uint64, bool) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.state.Block == nil {
+		return 0, false
+	}
+	return *collector.state.Block + 1, true
+}
+
+func (collector *EthSupply) HandleBlock(ctx context.Context, block *follower.Block) error {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	number := uint64(block.Number)
+	if collector.state.Block != nil && number <= *collector.state.Block {
+		return nil
+	}
+
+	baseFee := new(big.Int)
+	if block.BaseFeePerGas != nil {
+		baseFee = block.BaseFeePerGas.ToInt()
+	}
+
+	tips, err := collector.getTips(ctx, block, baseFee)
+	if err != nil {
+		return err
+	}
+
+	withdrawals := new(big.Int)
+	for _, withdrawal := range block.Withdrawals {
+		amount := new(big.Int).SetUint64(uint64(withdrawal.Amount))
+		withdrawals.Add(withdrawals, amount.Mul(amount, gweiToWei))
+	}
+
+	burned := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(uint64(block.GasUsed)))
+
+	state := supplyState{
+		Block:       &number,
+		Burned:      burned.Add(burned, collector.state.Burned),
+		Tips:        tips.Add(tips, collector.state.Tips),
+		Withdrawals: withdrawals.Add(withdrawals, collector.state.Withdrawals),
+	}
+	if err := collector.save(state); err != nil {
+		return err
+	}
+	collector.state = state
+
+	return nil
+}
+
+// getTips returns the priority fees paid by the transactions of block.
+func (collector *EthSupply) getTips(ctx context.Context, block *follower.Block, baseFee *big.Int) (*big.Int, error) {
+	tips := new(big.Int)
+	if len(block.Transactions) == 0 {
+		return tips, nil
+	}
+
+	client := collector.client()
+
+	// Receipts are queried by hash, so that those of a block that replaced
+	// this one in a reorg are not accounted for.
+	var receipts []*receiptResult
+	err := client.CallContext(ctx, &receipts, "eth_getBlockReceipts", block.Hash)
+	if isMethodNotFound(err) {
+		receipts = make([]*receiptResult, len(block.Transactions))
+		for i, tx := range block.Transactions {
+			if err := client.CallContext(ctx, &receipts[i], "eth_getTransactionReceipt", tx.Hash); err != nil {
+				return nil, err
+			}
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(receipts) != len(block.Transactions) {
+		return nil, errReceiptsMismatch
+	}
+
+	for _, receipt := range receipts {
+		if receipt == nil || receipt.EffectiveGasPrice == nil {
+			return nil, errReceiptNotFound
+		}
+		if receipt.BlockHash != block.Hash {
+			return nil, errReceiptsMismatch
+		}
+
+		tip := new(big.Int).Sub(receipt.EffectiveGasPrice.ToInt(), baseFee)
+		if tip.Sign() < 0 {
+			continue
+		}
+		tips.Add(tips, tip.Mul(tip, new(big.Int).SetUint64(uint64(receipt.GasUsed))))
+	}
+
+	return tips, nil
+}
+
+// save atomically writes state to the state file.
+func (collector *EthSupply) save(state supplyState) error {
+	if collector.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := collector.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, collector.path)
+}
+
+func (collector *EthSupply) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.burnedDesc
+	ch <- collector.tipsDesc
+	ch <- collector.withdrawalsDesc
+	ch <- collector.blockDesc
+}
+
+func (collector *EthSupply) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.Lock()
+	state := collector.state
+	collector.mu.Unlock()
+
+	value, _ := new(big.Float).SetInt(state.Burned).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.burnedDesc, prometheus.CounterValue, value)
+	value, _ = new(big.Float).SetInt(state.Tips).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.tipsDesc, prometheus.CounterValue, value)
+	value, _ = new(big.Float).SetInt(state.Withdrawals).Float64()
+	ch <- prometheus.MustNewConstMetric(collector.withdrawalsDesc, prometheus.CounterValue, value)
+
+	if state.Block != nil {
+		value = float64(*state.Block)
+		ch <- prometheus.MustNewConstMetric(collector.blockDesc, prometheus.GaugeValue, value)
+	}
+}
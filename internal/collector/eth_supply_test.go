@@ -0,0 +1,219 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var supplyBlockHash = common.HexToHash("0xb10c")
+
+// newReceiptsServer serves receipts of two transactions of the block with
+// supplyBlockHash paying 3 and 1 gwei per gas. Without blockReceipts only
+// eth_getTransactionReceipt is supported.
+func newReceiptsServer(t *testing.T, blockReceipts bool) *httptest.Server {
+	receipts := []string{
+		fmt.Sprintf(`{"blockHash": "%s", "gasUsed": "0x5208", "effectiveGasPrice": "0xb2d05e00"}`, supplyBlockHash.Hex()),
+		fmt.Sprintf(`{"blockHash": "%s", "gasUsed": "0x186a0", "effectiveGasPrice": "0x3b9aca00"}`, supplyBlockHash.Hex()),
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+			Params []string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32601, "message": "method not found"}}`, request.ID)
+		switch {
+		case request.Method == "eth_getBlockReceipts" && blockReceipts:
+			result := "null"
+			if common.HexToHash(request.Params[0]) == supplyBlockHash {
+				result = fmt.Sprintf(`[%s, %s]`, receipts[0], receipts[1])
+			}
+			response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, request.ID, result)
+		case request.Method == "eth_getTransactionReceipt":
+			i := common.HexToHash(request.Params[0]).Big().Int64()
+			response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, request.ID, receipts[i])
+		}
+
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func newSupplyBlock(number uint64) *follower.Block {
+	return &follower.Block{
+		Number:        hexutil.Uint64(number),
+		Hash:          supplyBlockHash,
+		GasUsed:       0x5208 + 0x186a0,
+		BaseFeePerGas: (*hexutil.Big)(big.NewInt(2e9)),
+		Transactions: []follower.Transaction{
+			{Hash: common.BigToHash(big.NewInt(0))},
+			{Hash: common.BigToHash(big.NewInt(1))},
+		},
+		Withdrawals: []follower.Withdrawal{
+			{Amount: 1e9},
+			{Amount: 17},
+		},
+	}
+}
+
+func newEthSupply(t *testing.T, url, path string) *EthSupply {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector, err := NewEthSupply(func() *rpc.Client { return client }, path)
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	return collector
+}
+
+func TestEthSupplyCollectNoBlocks(t *testing.T) {
+	collector := newEthSupply(t, "http://localhost", "")
+
+	want := map[string]float64{
+		"eth_burned_fees_wei_total":   0,
+		"eth_priority_fees_wei_total": 0,
+		"eth_withdrawals_wei_total":   0,
+	}
+	if got := gather(t, collector); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, ok := collector.Next(); ok {
+		t.Fatalf("expected no progress")
+	}
+}
+
+func TestEthSupplyHandleBlock(t *testing.T) {
+	for _, blockReceipts := range []bool{true, false} {
+		server := newReceiptsServer(t, blockReceipts)
+		defer server.Close()
+
+		collector := newEthSupply(t, server.URL, "")
+		if err := collector.HandleBlock(context.Background(), newSupplyBlock(10)); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		// Burned is 2 gwei * 121000, tips are 1 gwei * 21000 and withdrawals
+		// are 1 ether and 17 gwei.
+		want := map[string]float64{
+			"eth_burned_fees_wei_total":   242e12,
+			"eth_priority_fees_wei_total": 21e12,
+			"eth_withdrawals_wei_total":   1e18 + 17e9,
+			"eth_supply_processed_block":  10,
+		}
+		if got := gather(t, collector); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEthSupplyHandleBlockMismatch(t *testing.T) {
+	// Receipts of a replaced block, a missing receipt and receipts of another
+	// block are not accounted for.
+	replaced := newSupplyBlock(10)
+	replaced.Hash = common.HexToHash("0xdead")
+
+	missing := newSupplyBlock(10)
+	missing.Transactions = append(missing.Transactions, follower.Transaction{Hash: common.BigToHash(big.NewInt(2))})
+
+	for _, test := range []struct {
+		blockReceipts bool
+		block         *follower.Block
+	}{
+		{true, replaced},
+		{false, replaced},
+		{true, missing},
+	} {
+		server := newReceiptsServer(t, test.blockReceipts)
+		defer server.Close()
+
+		collector := newEthSupply(t, server.URL, "")
+		if err := collector.HandleBlock(context.Background(), test.block); err != errReceiptsMismatch {
+			t.Fatalf("unexpected error %#v", err)
+		}
+		if _, ok := collector.Next(); ok {
+			t.Fatalf("expected no progress")
+		}
+	}
+}
+
+func TestEthSupplyPersistence(t *testing.T) {
+	server := newReceiptsServer(t, true)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "supply.json")
+
+	collector := newEthSupply(t, server.URL, path)
+	if err := collector.HandleBlock(context.Background(), newSupplyBlock(10)); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	collector = newEthSupply(t, server.URL, path)
+	if next, ok := collector.Next(); !ok || next != 11 {
+		t.Fatalf("got %v, want 11", next)
+	}
+
+	// Blocks accounted for before the restart are not counted again.
+	if err := collector.HandleBlock(context.Background(), newSupplyBlock(10)); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if err := collector.HandleBlock(context.Background(), newSupplyBlock(11)); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{
+		"eth_burned_fees_wei_total":   484e12,
+		"eth_priority_fees_wei_total": 42e12,
+		"eth_withdrawals_wei_total":   2e18 + 34e9,
+		"eth_supply_processed_block":  11,
+	}
+	if got := gather(t, collector); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestEthSupplyCorruptState(t *testing.T) {
+	for _, data := range []string{
+		`{"block": 10, "burned": null, "tips": 0, "withdrawals": 0}`,
+		`{"block": 10, "burned": 0, "withdrawals": 0}`,
+		`{"burned": 0, "tips": 0, "withdrawals": 0}`,
+		`not json`,
+	} {
+		path := filepath.Join(t.TempDir(), "supply.json")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		server := newReceiptsServer(t, true)
+		collector := newEthSupply(t, server.URL, path)
+		if _, ok := collector.Next(); ok {
+			t.Fatalf("expected no progress for %v", data)
+		}
+
+		// Accounting starts afresh instead of failing on the corrupt state.
+		if err := collector.HandleBlock(context.Background(), newSupplyBlock(10)); err != nil {
+			t.Fatalf("unexpected error %#v for %v", err, data)
+		}
+		server.Close()
+	}
+}
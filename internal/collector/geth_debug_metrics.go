@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GethDebugMetrics translates the internal metrics of Geth returned by
+// debug_metrics into Prometheus metrics. As the set of metrics is only known
+// at scrape time, the collector is unchecked.
+type GethDebugMetrics struct {
+	rpc    *rpc.Client
+	filter *regexp.Regexp
+	desc   *prometheus.Desc
+}
+
+// debugMetricRates maps the rate fields of meters and timers to windows.
+var debugMetricRates = map[string]string{
+	"AvgRate01Min": "1m",
+	"AvgRate05Min": "5m",
+	"AvgRate15Min": "15m",
+	"MeanRate":     "mean",
+}
+
+// debugMetricFields maps the remaining numeric fields to metric name suffixes.
+var debugMetricFields = map[string]string{
+	"Value":        "",
+	"Overall":      "_count",
+	"Count":        "_count",
+	"Measurements": "_count",
+	"Mean":         "_mean",
+	"Min":          "_min",
+	"Max":          "_max",
+	"StdDev":       "_stddev",
+}
+
+var invalidMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// NewGethDebugMetrics returns a collector of the debug_metrics whose slash
+// separated names, e.g. "chain/inserts", match filter.
+func NewGethDebugMetrics(rpc *rpc.Client, filter *regexp.Regexp) *GethDebugMetrics {
+	return &GethDebugMetrics{
+		rpc:    rpc,
+		filter: filter,
+		desc: prometheus.NewDesc(
+			"geth_debug_metrics",
+			"number of metrics translated from debug_metrics",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *GethDebugMetrics) Describe(ch chan<- *prometheus.Desc) {}
+
+func (collector *GethDebugMetrics) Collect(ch chan<- prometheus.Metric) {
+	var result map[string]interface{}
+	if err := collector.rpc.Call(&result, "debug_metrics", true); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	scrape := &debugMetricsScrape{
+		ch:     ch,
+		filter: collector.filter,
+		labels: make(map[string]string),
+		series: make(map[string]bool),
+	}
+	scrape.walk(nil, result)
+
+	value := float64(len(scrape.series))
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
+
+type debugMetricsScrape struct {
+	ch     chan<- prometheus.Metric
+	filter *regexp.Regexp
+	// labels maps exported names to their label names.
+	labels map[string]string
+	series map[string]bool
+}
+
+// walk descends the metric hierarchy, in which names are split on slashes.
+func (scrape *debugMetricsScrape) walk(path []string, node map[string]interface{}) {
+	if isDebugMetric(node) {
+		if name := strings.Join(path, "/"); scrape.filter.MatchString(name) {
+			scrape.metric(metricName(name), node)
+		}
+		return
+	}
+
+	for _, key := range sortedKeys(node) {
+		switch child := node[key].(type) {
+		case map[string]interface{}:
+			scrape.walk(append(path, key), child)
+		case float64:
+			if name := strings.Join(append(path, key), "/"); scrape.filter.MatchString(name) {
+				scrape.send(metricName(name), "", nil, nil, child)
+			}
+		}
+	}
+}
+
+func (scrape *debugMetricsScrape) metric(name string, fields map[string]interface{}) {
+	for _, key := range sortedKeys(fields) {
+		switch value := fields[key].(type) {
+		case float64:
+			if window, ok := debugMetricRates[key]; ok {
+				scrape.send(name+"_rate", "rate of events per second over the window", []string{"window"}, []string{window}, value)
+			} else if suffix, ok := debugMetricFields[key]; ok {
+				scrape.send(name+suffix, "", nil, nil, value)
+			}
+		case map[string]interface{}:
+			if key != "Percentiles" {
+				continue
+			}
+			for _, p := range sortedKeys(value) {
+				percentile, err := strconv.ParseFloat(p, 64)
+				v, ok := value[p].(float64)
+				if err != nil || !ok {
+					continue
+				}
+				quantile := strconv.FormatFloat(percentile/100, 'g', -1, 64)
+				scrape.send(name, "", []string{"quantile"}, []string{quantile}, v)
+			}
+		}
+	}
+}
+
+// send exports a metric unless it clashes with an exported one, which may
+// happen as names are sanitized.
+func (scrape *debugMetricsScrape) send(name, help string, labels, values []string, value float64) {
+	labelNames := strings.Join(labels, ",")
+	if exported, ok := scrape.labels[name]; ok && exported != labelNames {
+		return
+	}
+	series := name + "{" + strings.Join(values, ",") + "}"
+	if scrape.series[series] {
+		return
+	}
+	scrape.labels[name] = labelNames
+	scrape.series[series] = true
+
+	if help == "" {
+		help = "debug_metrics value"
+	}
+	desc := prometheus.NewDesc(name, help, labels, nil)
+	scrape.ch <- prometheus.MustNewConstMetric(desc, prometheus.UntypedValue, value, values...)
+}
+
+// isDebugMetric reports whether node holds the fields of a metric rather
+// than a level of the hierarchy.
+func isDebugMetric(node map[string]interface{}) bool {
+	if _, ok := node["Percentiles"]; ok {
+		return true
+	}
+	for key := range node {
+		if _, ok := debugMetricRates[key]; ok {
+			return true
+		}
+		if _, ok := debugMetricFields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// metricName converts a name such as "chain/inserts" to "geth_chain_inserts".
+func metricName(name string) string {
+	return "geth_" + invalidMetricChars.ReplaceAllString(name, "_")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
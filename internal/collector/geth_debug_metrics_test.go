@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/upstream"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestGethDebugMetricsCollectError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewGethDebugMetrics(rpc, regexp.MustCompile(""))
+	ch := make(chan prometheus.Metric, 1)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	var metric dto.Metric
+	err = (<-ch).Write(&metric)
+	if _, ok := err.(*url.Error); !ok {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestGethDebugMetricsCollect(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": {
+			"chain": {
+				"inserts": {"AvgRate01Min": 0.2, "AvgRate05Min": 0.19, "AvgRate15Min": 0.18, "MeanRate": 0.17, "Overall": 1200, "Percentiles": {"5": 1000, "50": 2000, "95": 9000}},
+				"head": {"block": {"Value": 17000000}}
+			},
+			"p2p": {
+				"ingress": {"AvgRate01Min": 1024, "AvgRate05Min": 1000, "AvgRate15Min": 990, "MeanRate": 980, "Overall": 123456}
+			},
+			"txpool": {
+				"pending": {"Value": 42},
+				"invalid": "Unknown metric type"
+			}
+		}}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewGethDebugMetrics(rpc, regexp.MustCompile(`^(chain|txpool)/`))
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	got := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			name := family.GetName()
+			for _, label := range metric.Label {
+				name += "/" + label.GetValue()
+			}
+			if metric.Untyped != nil {
+				got[name] = metric.Untyped.GetValue()
+			} else {
+				got[name] = metric.Gauge.GetValue()
+			}
+		}
+	}
+
+	want := map[string]float64{
+		"geth_chain_inserts/0.05":      1000,
+		"geth_chain_inserts/0.5":       2000,
+		"geth_chain_inserts/0.95":      9000,
+		"geth_chain_inserts_count":     1200,
+		"geth_chain_inserts_rate/1m":   0.2,
+		"geth_chain_inserts_rate/5m":   0.19,
+		"geth_chain_inserts_rate/15m":  0.18,
+		"geth_chain_inserts_rate/mean": 0.17,
+		"geth_chain_head_block":        17000000,
+		"geth_txpool_pending":          42,
+		"geth_debug_metrics":           10,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Fatalf("got %v for %v, want %v", got[name], name, value)
+		}
+	}
+}
+
+func TestGethDebugMetricsClash(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": {"a": {"b.c": {"Value": 1}, "b-c": {"Value": 2}}}}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewGethDebugMetrics(rpc, regexp.MustCompile("")))
+
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestGethDebugMetricsCollectUpstream(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": {"txpool": {"pending": {"Value": 42}}}}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	pool, err := upstream.NewPool([]upstream.Target{{Name: "node", URL: rpcServer.URL}}, nil, time.Second)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	defer pool.Close()
+
+	upstreams := upstream.NewCollector(pool, func(rpc *rpc.Client) (chain, node []prometheus.Collector) {
+		return nil, []prometheus.Collector{NewGethDebugMetrics(rpc, regexp.MustCompile(""))}
+	})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(upstreams, upstreams.Unchecked())
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	var got []string
+	for _, family := range families {
+		got = append(got, family.GetName())
+	}
+	want := "[ethereum_exporter_active_upstream geth_debug_metrics geth_txpool_pending]"
+	if fmt.Sprint(got) != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
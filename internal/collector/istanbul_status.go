@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type IstanbulStatus struct {
+	rpc          *rpc.Client
+	blocksDesc   *prometheus.Desc
+	proposedDesc *prometheus.Desc
+}
+
+type istanbulStatusResult struct {
+	NumBlocks      uint64
+	SealerActivity map[common.Address]uint64
+}
+
+func NewIstanbulStatus(rpc *rpc.Client) *IstanbulStatus {
+	return &IstanbulStatus{
+		rpc: rpc,
+		blocksDesc: prometheus.NewDesc(
+			"bft_status_blocks",
+			"number of blocks in the recent window",
+			nil,
+			nil,
+		),
+		proposedDesc: prometheus.NewDesc(
+			"bft_validator_proposed_blocks",
+			"number of blocks proposed by the validator in the recent window",
+			[]string{"validator"},
+			nil,
+		),
+	}
+}
+
+func (collector *IstanbulStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.blocksDesc
+	ch <- collector.proposedDesc
+}
+
+func (collector *IstanbulStatus) Collect(ch chan<- prometheus.Metric) {
+	var result *istanbulStatusResult
+	if err := collector.rpc.Call(&result, "istanbul_status"); err != nil {
+		// Nodes running another consensus engine are not an error.
+		if isMethodNotFound(err) {
+			return
+		}
+		ch <- prometheus.NewInvalidMetric(collector.blocksDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.proposedDesc, err)
+		return
+	}
+
+	value := float64(result.NumBlocks)
+	ch <- prometheus.MustNewConstMetric(collector.blocksDesc, prometheus.GaugeValue, value)
+
+	for validator, count := range result.SealerActivity {
+		ch <- prometheus.MustNewConstMetric(collector.proposedDesc, prometheus.GaugeValue, float64(count), validator.Hex())
+	}
+}
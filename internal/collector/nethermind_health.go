@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NethermindHealth struct {
+	rpc         *rpc.Client
+	healthyDesc *prometheus.Desc
+	syncingDesc *prometheus.Desc
+	errorDesc   *prometheus.Desc
+}
+
+type nodeStatusResult struct {
+	Healthy   bool
+	IsSyncing bool
+	// Errors lists the failing checks, e.g. "NoPeers" or "ClUnavailable".
+	Errors []string
+}
+
+func NewNethermindHealth(rpc *rpc.Client) *NethermindHealth {
+	return &NethermindHealth{
+		rpc: rpc,
+		healthyDesc: prometheus.NewDesc(
+			"nethermind_healthy",
+			"whether all health checks of the node pass",
+			nil,
+			nil,
+		),
+		syncingDesc: prometheus.NewDesc(
+			"nethermind_health_syncing",
+			"whether the health checks consider the node syncing",
+			nil,
+			nil,
+		),
+		errorDesc: prometheus.NewDesc(
+			"nethermind_health_check_failing",
+			"failing health checks of the node",
+			[]string{"check"},
+			nil,
+		),
+	}
+}
+
+func (collector *NethermindHealth) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.healthyDesc
+	ch <- collector.syncingDesc
+	ch <- collector.errorDesc
+}
+
+func (collector *NethermindHealth) Collect(ch chan<- prometheus.Metric) {
+	var result *nodeStatusResult
+	if err := collector.rpc.Call(&result, "health_nodeStatus"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.healthyDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.syncingDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.errorDesc, err)
+		return
+	}
+
+	value := 0.0
+	if result.Healthy {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(collector.healthyDesc, prometheus.GaugeValue, value)
+
+	value = 0.0
+	if result.IsSyncing {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(collector.syncingDesc, prometheus.GaugeValue, value)
+
+	failing := make(map[string]bool)
+	for _, check := range result.Errors {
+		if !failing[check] {
+			failing[check] = true
+			ch <- prometheus.MustNewConstMetric(collector.errorDesc, prometheus.GaugeValue, 1, check)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NethermindLocalEnode struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewNethermindLocalEnode(rpc *rpc.Client) *NethermindLocalEnode {
+	return &NethermindLocalEnode{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"nethermind_local_enode_info",
+			"enode URL of this node",
+			[]string{"enode"},
+			nil,
+		),
+	}
+}
+
+func (collector *NethermindLocalEnode) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *NethermindLocalEnode) Collect(ch chan<- prometheus.Metric) {
+	var result string
+	if err := collector.rpc.Call(&result, "net_localEnode"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, 1, result)
+}
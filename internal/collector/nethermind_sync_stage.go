@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// nethermindSyncStages are the sync modes Nethermind reports, several of
+// which may be active at once.
+var nethermindSyncStages = []string{
+	"None",
+	"WaitingForBlock",
+	"Disconnected",
+	"FastBlocks",
+	"FastSync",
+	"StateNodes",
+	"Full",
+	"DbLoad",
+	"FastHeaders",
+	"FastBodies",
+	"FastReceipts",
+	"SnapSync",
+	"BeaconHeaders",
+	"UpdatingPivot",
+}
+
+type NethermindSyncStage struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+type syncStageResult struct {
+	CurrentStage string
+}
+
+func NewNethermindSyncStage(rpc *rpc.Client) *NethermindSyncStage {
+	return &NethermindSyncStage{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"nethermind_sync_stage",
+			"whether the sync stage is active",
+			[]string{"stage"},
+			nil,
+		),
+	}
+}
+
+func (collector *NethermindSyncStage) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *NethermindSyncStage) Collect(ch chan<- prometheus.Metric) {
+	var result *syncStageResult
+	if err := collector.rpc.Call(&result, "debug_getSyncStage"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	// Stages are reported as a comma separated list, e.g. "FastHeaders, FastBodies".
+	active := make(map[string]bool)
+	for _, stage := range strings.Split(result.CurrentStage, ",") {
+		if stage = strings.TrimSpace(stage); stage != "" {
+			active[stage] = true
+		}
+	}
+
+	for _, stage := range nethermindSyncStages {
+		var value float64
+		if active[stage] {
+			value = 1
+			delete(active, stage)
+		}
+		ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value, stage)
+	}
+	for stage := range active {
+		ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, 1, stage)
+	}
+}
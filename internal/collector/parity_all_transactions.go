@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ParityAllTransactions struct {
+	rpc         *rpc.Client
+	countDesc   *prometheus.Desc
+	sendersDesc *prometheus.Desc
+	gasDesc     *prometheus.Desc
+}
+
+type queuedTransactionResult struct {
+	From common.Address
+	Gas  hexutil.Uint64
+}
+
+func NewParityAllTransactions(rpc *rpc.Client) *ParityAllTransactions {
+	return &ParityAllTransactions{
+		rpc: rpc,
+		countDesc: prometheus.NewDesc(
+			"parity_queued_transactions",
+			"number of transactions in the queue, both pending and future",
+			nil,
+			nil,
+		),
+		sendersDesc: prometheus.NewDesc(
+			"parity_queued_transactions_senders",
+			"number of distinct senders of queued transactions",
+			nil,
+			nil,
+		),
+		gasDesc: prometheus.NewDesc(
+			"parity_queued_transactions_gas",
+			"total gas limit of queued transactions",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *ParityAllTransactions) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.countDesc
+	ch <- collector.sendersDesc
+	ch <- collector.gasDesc
+}
+
+func (collector *ParityAllTransactions) Collect(ch chan<- prometheus.Metric) {
+	var result []*queuedTransactionResult
+	if err := collector.rpc.Call(&result, "parity_allTransactions"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.countDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.sendersDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.gasDesc, err)
+		return
+	}
+
+	senders := make(map[common.Address]struct{})
+	var gas float64
+	for _, tx := range result {
+		senders[tx.From] = struct{}{}
+		gas += float64(tx.Gas)
+	}
+
+	value := float64(len(result))
+	ch <- prometheus.MustNewConstMetric(collector.countDesc, prometheus.GaugeValue, value)
+	value = float64(len(senders))
+	ch <- prometheus.MustNewConstMetric(collector.sendersDesc, prometheus.GaugeValue, value)
+	ch <- prometheus.MustNewConstMetric(collector.gasDesc, prometheus.GaugeValue, gas)
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ParityChainStatus struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+type chainStatusResult struct {
+	// BlockGap holds the first and the last missing block, if any.
+	BlockGap []hexutil.Uint64
+}
+
+func NewParityChainStatus(rpc *rpc.Client) *ParityChainStatus {
+	return &ParityChainStatus{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"parity_chain_block_gap",
+			"number of ancient blocks missing from the database",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *ParityChainStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *ParityChainStatus) Collect(ch chan<- prometheus.Metric) {
+	var result *chainStatusResult
+	if err := collector.rpc.Call(&result, "parity_chainStatus"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	var value float64
+	if gap := result.BlockGap; len(gap) == 2 && gap[1] >= gap[0] {
+		value = float64(gap[1] - gap[0] + 1)
+	}
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value)
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parityModes are the operating modes of parity_mode.
+var parityModes = []string{"active", "passive", "dark", "offline"}
+
+type ParityMode struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+func NewParityMode(rpc *rpc.Client) *ParityMode {
+	return &ParityMode{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"parity_mode",
+			"whether this node operates in the mode",
+			[]string{"mode"},
+			nil,
+		),
+	}
+}
+
+func (collector *ParityMode) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *ParityMode) Collect(ch chan<- prometheus.Metric) {
+	var result string
+	if err := collector.rpc.Call(&result, "parity_mode"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	for _, mode := range parityModes {
+		var value float64
+		if mode == result {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, value, mode)
+	}
+}
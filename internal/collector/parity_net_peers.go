@@ -1,6 +1,8 @@
 package collector
 
 import (
+	"strconv"
+
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -9,11 +11,23 @@ type ParityNetPeers struct {
 	rpc           *rpc.Client
 	activeDesc    *prometheus.Desc
 	connectedDesc *prometheus.Desc
+	maxDesc       *prometheus.Desc
+	protocolDesc  *prometheus.Desc
 }
 
 type peersResult struct {
 	Active    uint64
 	Connected uint64
+	Max       uint64
+	Peers     []*peerResult
+}
+
+type peerResult struct {
+	// Protocols maps protocol names to their state, which is null for
+	// protocols the peer does not speak.
+	Protocols map[string]*struct {
+		Version uint64
+	}
 }
 
 func NewParityNetPeers(rpc *rpc.Client) *ParityNetPeers {
@@ -31,12 +45,26 @@ func NewParityNetPeers(rpc *rpc.Client) *ParityNetPeers {
 			nil,
 			nil,
 		),
+		maxDesc: prometheus.NewDesc(
+			"parity_net_max_peers",
+			"maximum number of peers this client connects to",
+			nil,
+			nil,
+		),
+		protocolDesc: prometheus.NewDesc(
+			"parity_net_protocol_peers",
+			"number of connected peers by protocol and version",
+			[]string{"protocol", "version"},
+			nil,
+		),
 	}
 }
 
 func (collector *ParityNetPeers) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.activeDesc
 	ch <- collector.connectedDesc
+	ch <- collector.maxDesc
+	ch <- collector.protocolDesc
 }
 
 func (collector *ParityNetPeers) Collect(ch chan<- prometheus.Metric) {
@@ -44,6 +72,8 @@ func (collector *ParityNetPeers) Collect(ch chan<- prometheus.Metric) {
 	if err := collector.rpc.Call(&result, "parity_netPeers"); err != nil {
 		ch <- prometheus.NewInvalidMetric(collector.activeDesc, err)
 		ch <- prometheus.NewInvalidMetric(collector.connectedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.maxDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.protocolDesc, err)
 		return
 	}
 
@@ -51,4 +81,23 @@ func (collector *ParityNetPeers) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(collector.activeDesc, prometheus.GaugeValue, value)
 	value = float64(result.Connected)
 	ch <- prometheus.MustNewConstMetric(collector.connectedDesc, prometheus.GaugeValue, value)
+	value = float64(result.Max)
+	ch <- prometheus.MustNewConstMetric(collector.maxDesc, prometheus.GaugeValue, value)
+
+	type protocol struct {
+		name    string
+		version uint64
+	}
+	peers := make(map[protocol]uint64)
+	for _, peer := range result.Peers {
+		for name, state := range peer.Protocols {
+			if state != nil {
+				peers[protocol{name, state.Version}]++
+			}
+		}
+	}
+	for p, count := range peers {
+		version := strconv.FormatUint(p.version, 10)
+		ch <- prometheus.MustNewConstMetric(collector.protocolDesc, prometheus.GaugeValue, float64(count), p.name, version)
+	}
 }
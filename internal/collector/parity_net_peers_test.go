@@ -18,13 +18,13 @@ func TestParityNetPeersCollectError(t *testing.T) {
 	}
 
 	collector := NewParityNetPeers(rpc)
-	ch := make(chan prometheus.Metric, 2)
+	ch := make(chan prometheus.Metric, 4)
 
 	collector.Collect(ch)
 	close(ch)
 
-	if got := len(ch); got != 2 {
-		t.Fatalf("got %v, want 2", got)
+	if got := len(ch); got != 4 {
+		t.Fatalf("got %v, want 4", got)
 	}
 
 	var metric dto.Metric
@@ -41,7 +41,7 @@ func TestParityNetPeersCollectError(t *testing.T) {
 
 func TestParityNetPeersCollect(t *testing.T) {
 	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, err := w.Write([]byte(`{"result": {"active": 1, "connected": 25}}"`))
+		_, err := w.Write([]byte(`{"result": {"active": 1, "connected": 25, "max": 50, "peers": [{"protocols": {"eth": {"version": 63}, "pip": null}}, {"protocols": {"eth": {"version": 63}, "pip": null}}, {"protocols": {"eth": {"version": 64}, "pip": {"version": 1}}}]}}"`))
 		if err != nil {
 			t.Fatalf("could not write a response: %#v", err)
 		}
@@ -54,13 +54,13 @@ func TestParityNetPeersCollect(t *testing.T) {
 	}
 
 	collector := NewParityNetPeers(rpc)
-	ch := make(chan prometheus.Metric, 2)
+	ch := make(chan prometheus.Metric, 6)
 
 	collector.Collect(ch)
 	close(ch)
 
-	if got := len(ch); got != 2 {
-		t.Fatalf("got %v, want 2", got)
+	if got := len(ch); got != 6 {
+		t.Fatalf("got %v, want 6", got)
 	}
 
 	var (
@@ -89,4 +89,27 @@ func TestParityNetPeersCollect(t *testing.T) {
 	if got := *metric.Gauge.Value; got != 25 {
 		t.Fatalf("got %v, want 25", got)
 	}
+
+	result = <-ch
+	if err := result.Write(&metric); err != nil {
+		t.Fatalf("expected metric, got %#v", err)
+	}
+	if got := *metric.Gauge.Value; got != 50 {
+		t.Fatalf("got %v, want 50", got)
+	}
+
+	want := map[string]float64{"eth/63": 2, "eth/64": 1, "pip/1": 1}
+	for result := range ch {
+		if err := result.Write(&metric); err != nil {
+			t.Fatalf("expected metric, got %#v", err)
+		}
+		labels := make(map[string]string)
+		for _, label := range metric.Label {
+			labels[label.GetName()] = label.GetValue()
+		}
+		protocol := labels["protocol"] + "/" + labels["version"]
+		if got := *metric.Gauge.Value; got != want[protocol] {
+			t.Fatalf("got %v, want %v for %v", got, want[protocol], protocol)
+		}
+	}
 }
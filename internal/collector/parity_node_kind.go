@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ParityNodeKind struct {
+	rpc  *rpc.Client
+	desc *prometheus.Desc
+}
+
+type nodeKindResult struct {
+	Availability string
+	Capability   string
+}
+
+func NewParityNodeKind(rpc *rpc.Client) *ParityNodeKind {
+	return &ParityNodeKind{
+		rpc: rpc,
+		desc: prometheus.NewDesc(
+			"parity_node_kind_info",
+			"availability and capability of this node",
+			[]string{"availability", "capability"},
+			nil,
+		),
+	}
+}
+
+func (collector *ParityNodeKind) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *ParityNodeKind) Collect(ch chan<- prometheus.Metric) {
+	var result *nodeKindResult
+	if err := collector.rpc.Call(&result, "parity_nodeKind"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, 1, result.Availability, result.Capability)
+}
@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ParityPendingTransactionsStats struct {
+	rpc              *rpc.Client
+	trackedDesc      *prometheus.Desc
+	unpropagatedDesc *prometheus.Desc
+	propagationsDesc *prometheus.Desc
+}
+
+type transactionStatsResult struct {
+	// PropagatedTo maps peer IDs to the number of times the transaction
+	// was sent to them.
+	PropagatedTo map[string]uint64
+}
+
+func NewParityPendingTransactionsStats(rpc *rpc.Client) *ParityPendingTransactionsStats {
+	return &ParityPendingTransactionsStats{
+		rpc: rpc,
+		trackedDesc: prometheus.NewDesc(
+			"parity_pending_transactions_tracked",
+			"number of pending transactions with propagation stats",
+			nil,
+			nil,
+		),
+		unpropagatedDesc: prometheus.NewDesc(
+			"parity_pending_transactions_unpropagated",
+			"number of pending transactions not yet sent to any peer",
+			nil,
+			nil,
+		),
+		propagationsDesc: prometheus.NewDesc(
+			"parity_pending_transactions_propagations",
+			"number of times pending transactions were sent to peers",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (collector *ParityPendingTransactionsStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.trackedDesc
+	ch <- collector.unpropagatedDesc
+	ch <- collector.propagationsDesc
+}
+
+func (collector *ParityPendingTransactionsStats) Collect(ch chan<- prometheus.Metric) {
+	var result map[common.Hash]*transactionStatsResult
+	if err := collector.rpc.Call(&result, "parity_pendingTransactionsStats"); err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.trackedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.unpropagatedDesc, err)
+		ch <- prometheus.NewInvalidMetric(collector.propagationsDesc, err)
+		return
+	}
+
+	var unpropagated, propagations uint64
+	for _, stats := range result {
+		if len(stats.PropagatedTo) == 0 {
+			unpropagated++
+		}
+		for _, count := range stats.PropagatedTo {
+			propagations += count
+		}
+	}
+
+	value := float64(len(result))
+	ch <- prometheus.MustNewConstMetric(collector.trackedDesc, prometheus.GaugeValue, value)
+	value = float64(unpropagated)
+	ch <- prometheus.MustNewConstMetric(collector.unpropagatedDesc, prometheus.GaugeValue, value)
+	value = float64(propagations)
+	ch <- prometheus.MustNewConstMetric(collector.propagationsDesc, prometheus.GaugeValue, value)
+}
@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoW is a set of proof-of-work collectors that is disabled for good once
+// the chain has passed the terminal total difficulty, which is when block
+// difficulty drops to zero.
+type PoW struct {
+	rpc        *rpc.Client
+	collectors []prometheus.Collector
+	disabled   atomic.Bool
+}
+
+func NewPoW(rpc *rpc.Client) *PoW {
+	return &PoW{
+		rpc: rpc,
+		collectors: []prometheus.Collector{
+			NewEthHashrate(rpc),
+			NewEthMining(rpc),
+			NewEthCoinbase(rpc),
+			NewEthDifficulty(rpc),
+		},
+	}
+}
+
+func (collector *PoW) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range collector.collectors {
+		c.Describe(ch)
+	}
+}
+
+func (collector *PoW) Collect(ch chan<- prometheus.Metric) {
+	if collector.disabled.Load() {
+		return
+	}
+
+	// If the latest block is unavailable, the collectors report the error.
+	result, err := getBlockByNumber(context.Background(), collector.rpc, "latest")
+	if err == nil && result.Difficulty.ToInt().Sign() == 0 {
+		collector.disabled.Store(true)
+		return
+	}
+
+	for _, c := range collector.collectors {
+		c.Collect(ch)
+	}
+}
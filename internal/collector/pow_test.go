@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newPoWServer serves a proof-of-work node whose latest block has the
+// difficulty returned by the given function.
+func newPoWServer(t *testing.T, difficulty func() string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		results := map[string]string{
+			"eth_getBlockByNumber": `{"number": "0x1", "difficulty": "` + difficulty() + `"}`,
+			"eth_hashrate":         `"0xc94"`,
+			"eth_mining":           `true`,
+			"eth_coinbase":         `"0x7ffc57839b00206d1ad20c69a1981b489f772031"`,
+		}
+		response := `{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, "result": ` + results[request.Method] + `}`
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func TestPoWCollect(t *testing.T) {
+	var merged atomic.Bool
+	rpcServer := newPoWServer(t, func() string {
+		if merged.Load() {
+			return "0x0"
+		}
+		return "0x2c6ea3a2c5d5"
+	})
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	collector := NewPoW(rpc)
+	ch := make(chan prometheus.Metric, 4)
+
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 4 {
+		t.Fatalf("got %v, want 4", got)
+	}
+
+	merged.Store(true)
+	ch = make(chan prometheus.Metric, 4)
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 0 {
+		t.Fatalf("got %v, want 0 after the merge", got)
+	}
+
+	// The set stays disabled even if a later block reports difficulty.
+	merged.Store(false)
+	ch = make(chan prometheus.Metric, 4)
+	collector.Collect(ch)
+	close(ch)
+
+	if got := len(ch); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+}
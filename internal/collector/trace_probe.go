@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var errUnsupportedTraceMethod = errors.New("unsupported trace method")
+
+// TraceProbe periodically traces a block to measure the latency of the
+// tracing APIs served by archive nodes.
+type TraceProbe struct {
+	client  follower.ClientFunc
+	method  string
+	tracer  string
+	block   uint64
+	timeout time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	probed   bool
+	success  bool
+	duration time.Duration
+	size     int
+	number   uint64
+
+	successDesc  *prometheus.Desc
+	durationDesc *prometheus.Desc
+	sizeDesc     *prometheus.Desc
+	blockDesc    *prometheus.Desc
+}
+
+// NewTraceProbe returns a probe that traces block, or the most recent one if
+// block is 0, with method, either trace_block or debug_traceBlockByNumber.
+// The tracer only applies to the latter and is the default one if empty.
+func NewTraceProbe(client follower.ClientFunc, method, tracer string, block uint64, timeout time.Duration) (*TraceProbe, error) {
+	if method != "trace_block" && method != "debug_traceBlockByNumber" {
+		return nil, errUnsupportedTraceMethod
+	}
+
+	return &TraceProbe{
+		client:  client,
+		method:  method,
+		tracer:  tracer,
+		block:   block,
+		timeout: timeout,
+		now:     time.Now,
+		successDesc: prometheus.NewDesc(
+			"trace_probe_success",
+			"whether the last trace of a block succeeded",
+			[]string{"method"},
+			nil,
+		),
+		durationDesc: prometheus.NewDesc(
+			"trace_probe_duration_seconds",
+			"duration of the last trace of a block",
+			[]string{"method"},
+			nil,
+		),
+		sizeDesc: prometheus.NewDesc(
+			"trace_probe_response_bytes",
+			"size of the result of the last trace of a block",
+			[]string{"method"},
+			nil,
+		),
+		blockDesc: prometheus.NewDesc(
+			"trace_probe_block",
+			"number of the block traced last",
+			[]string{"method"},
+			nil,
+		),
+	}, nil
+}
+
+func (probe *TraceProbe) Describe(ch chan<- *prometheus.Desc) {
+	ch <- probe.successDesc
+	ch <- probe.durationDesc
+	ch <- probe.sizeDesc
+	ch <- probe.blockDesc
+}
+
+func (probe *TraceProbe) Collect(ch chan<- prometheus.Metric) {
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	if !probe.probed {
+		return
+	}
+
+	value := 0.0
+	if probe.success {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(probe.successDesc, prometheus.GaugeValue, value, probe.method)
+	value = probe.duration.Seconds()
+	ch <- prometheus.MustNewConstMetric(probe.durationDesc, prometheus.GaugeValue, value, probe.method)
+	value = float64(probe.size)
+	ch <- prometheus.MustNewConstMetric(probe.sizeDesc, prometheus.GaugeValue, value, probe.method)
+	value = float64(probe.number)
+	ch <- prometheus.MustNewConstMetric(probe.blockDesc, prometheus.GaugeValue, value, probe.method)
+}
+
+// Run probes every interval until ctx is done.
+func (probe *TraceProbe) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := probe.Probe(ctx); err != nil {
+			log.Printf("trace probe: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe traces the block once and records the outcome.
+func (probe *TraceProbe) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, probe.timeout)
+	defer cancel()
+
+	client := probe.client()
+
+	number := probe.block
+	if number == 0 {
+		var head hexutil.Uint64
+		if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+			probe.record(false, 0, 0, 0)
+			return err
+		}
+		number = uint64(head)
+	}
+
+	args := []interface{}{hexutil.EncodeUint64(number)}
+	if probe.method == "debug_traceBlockByNumber" && probe.tracer != "" {
+		args = append(args, map[string]string{"tracer": probe.tracer})
+	}
+
+	var result json.RawMessage
+	start := probe.now()
+	err := client.CallContext(ctx, &result, probe.method, args...)
+	probe.record(err == nil, probe.now().Sub(start), len(result), number)
+
+	return err
+}
+
+func (probe *TraceProbe) record(success bool, duration time.Duration, size int, number uint64) {
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	probe.probed = true
+	probe.success = success
+	probe.duration = duration
+	probe.size = size
+	probe.number = number
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const traceResult = `[{"result": {"type": "CALL", "gasUsed": "0x5208"}}]`
+
+// newTraceServer serves a chain at block 0x64 whose blocks can be traced
+// with debug_traceBlockByNumber and the callTracer.
+func newTraceServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+			Params []json.RawMessage
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32601, "message": "method not found"}}`, request.ID)
+		switch request.Method {
+		case "eth_blockNumber":
+			response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": "0x64"}`, request.ID)
+		case "debug_traceBlockByNumber":
+			if got := string(request.Params[1]); got != `{"tracer":"callTracer"}` {
+				t.Errorf("got %v, want the callTracer", got)
+			}
+			response = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, request.ID, traceResult)
+		}
+
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func newTraceProbe(t *testing.T, url, method string, block uint64) *TraceProbe {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	probe, err := NewTraceProbe(func() *rpc.Client { return client }, method, "callTracer", block, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	// Every call to now advances the clock by 1.5 seconds.
+	now := time.Unix(0, 0)
+	probe.now = func() time.Time {
+		now = now.Add(1500 * time.Millisecond)
+		return now
+	}
+	return probe
+}
+
+func TestNewTraceProbeUnsupportedMethod(t *testing.T) {
+	if _, err := NewTraceProbe(nil, "eth_call", "", 0, time.Second); err != errUnsupportedTraceMethod {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestTraceProbeCollectNotProbed(t *testing.T) {
+	probe := newTraceProbe(t, "http://localhost", "trace_block", 0)
+
+	if got := len(gather(t, probe)); got != 0 {
+		t.Fatalf("got %v, want 0", got)
+	}
+}
+
+func TestTraceProbeProbeError(t *testing.T) {
+	probe := newTraceProbe(t, "http://localhost", "trace_block", 0)
+
+	err := probe.Probe(context.Background())
+	if _, ok := err.(*url.Error); !ok {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	want := map[string]float64{
+		"trace_probe_success{trace_block}":          0,
+		"trace_probe_duration_seconds{trace_block}": 0,
+		"trace_probe_response_bytes{trace_block}":   0,
+		"trace_probe_block{trace_block}":            0,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTraceProbeProbe(t *testing.T) {
+	rpcServer := newTraceServer(t)
+	defer rpcServer.Close()
+
+	probe := newTraceProbe(t, rpcServer.URL, "debug_traceBlockByNumber", 0)
+	if err := probe.Probe(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{
+		"trace_probe_success{debug_traceBlockByNumber}":          1,
+		"trace_probe_duration_seconds{debug_traceBlockByNumber}": 1.5,
+		"trace_probe_response_bytes{debug_traceBlockByNumber}":   float64(len(traceResult)),
+		"trace_probe_block{debug_traceBlockByNumber}":            100,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTraceProbeProbeBlock(t *testing.T) {
+	rpcServer := newTraceServer(t)
+	defer rpcServer.Close()
+
+	probe := newTraceProbe(t, rpcServer.URL, "trace_block", 42)
+	if err := probe.Probe(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The server does not serve trace_block, so the probe fails quickly.
+	want := map[string]float64{
+		"trace_probe_success{trace_block}":          0,
+		"trace_probe_duration_seconds{trace_block}": 1.5,
+		"trace_probe_response_bytes{trace_block}":   0,
+		"trace_probe_block{trace_block}":            42,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,280 @@
+package collector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	errNoChainsAllowed = errors.New("no chain IDs allowed")
+	errChainNotAllowed = errors.New("chain ID not allowed")
+	errTxNotIncluded   = errors.New("transaction not included")
+)
+
+// txProbeGas is the gas limit of a plain transfer.
+const txProbeGas = 21000
+
+// txProbeReplacementBump is the percentage by which the gas price of a
+// transaction replacing a stuck one is raised. Nodes require at least 10%.
+const txProbeReplacementBump = 125
+
+// TxProbe periodically sends a transaction of zero value from the probe
+// account to itself and waits for its inclusion. As this spends funds, it
+// only runs on allowed chains.
+type TxProbe struct {
+	client       follower.ClientFunc
+	key          *ecdsa.PrivateKey
+	address      common.Address
+	chainIDs     map[uint64]bool
+	pollInterval time.Duration
+	timeout      time.Duration
+	now          func() time.Time
+
+	// lastNonce and lastGasPrice are those of the last sent transaction,
+	// used to replace it if it is stuck. They are only used by Probe.
+	lastNonce    uint64
+	lastGasPrice *big.Int
+
+	mu        sync.Mutex
+	probed    bool
+	submitted bool
+	included  bool
+	blocks    uint64
+	latency   prometheus.Histogram
+
+	submittedDesc *prometheus.Desc
+	includedDesc  *prometheus.Desc
+	blocksDesc    *prometheus.Desc
+}
+
+// NewTxProbe returns a probe sending transactions signed with key on the
+// chains with the given IDs. Receipts are polled every pollInterval until
+// the transaction is included or timeout elapses.
+func NewTxProbe(client follower.ClientFunc, key *ecdsa.PrivateKey, chainIDs []uint64, pollInterval, timeout time.Duration) (*TxProbe, error) {
+	if len(chainIDs) == 0 {
+		return nil, errNoChainsAllowed
+	}
+
+	allowed := make(map[uint64]bool)
+	for _, id := range chainIDs {
+		allowed[id] = true
+	}
+
+	return &TxProbe{
+		client:       client,
+		key:          key,
+		address:      crypto.PubkeyToAddress(key.PublicKey),
+		chainIDs:     allowed,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+		now:          time.Now,
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tx_probe_inclusion_seconds",
+			Help:    "time from submission to inclusion of probe transactions",
+			Buckets: []float64{1, 2, 4, 8, 12, 16, 24, 36, 48, 60, 90, 120, 180, 300},
+		}),
+		submittedDesc: prometheus.NewDesc(
+			"tx_probe_submission_success",
+			"whether the last probe transaction was accepted by the node",
+			nil,
+			nil,
+		),
+		includedDesc: prometheus.NewDesc(
+			"tx_probe_included",
+			"whether the last probe transaction was included before the timeout",
+			nil,
+			nil,
+		),
+		blocksDesc: prometheus.NewDesc(
+			"tx_probe_inclusion_blocks",
+			"number of blocks from submission to inclusion of the last included probe transaction",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+func (probe *TxProbe) Describe(ch chan<- *prometheus.Desc) {
+	ch <- probe.submittedDesc
+	ch <- probe.includedDesc
+	ch <- probe.blocksDesc
+	probe.latency.Describe(ch)
+}
+
+func (probe *TxProbe) Collect(ch chan<- prometheus.Metric) {
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	probe.latency.Collect(ch)
+
+	if !probe.probed {
+		return
+	}
+
+	value := 0.0
+	if probe.submitted {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(probe.submittedDesc, prometheus.GaugeValue, value)
+
+	value = 0.0
+	if probe.included {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(probe.includedDesc, prometheus.GaugeValue, value)
+
+	value = float64(probe.blocks)
+	ch <- prometheus.MustNewConstMetric(probe.blocksDesc, prometheus.GaugeValue, value)
+}
+
+// Run probes every interval until ctx is done.
+func (probe *TxProbe) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := probe.Probe(ctx); err != nil {
+			log.Printf("transaction probe: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe sends a transaction and waits for its inclusion once.
+func (probe *TxProbe) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, probe.timeout)
+	defer cancel()
+
+	tx, head, err := probe.send(ctx)
+	if err != nil {
+		probe.record(false, false, 0)
+		return err
+	}
+	sent := probe.now()
+	client := probe.client()
+
+	ticker := time.NewTicker(probe.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are retried until the timeout, as the node may be busy.
+		var receipt *struct {
+			BlockNumber hexutil.Uint64
+		}
+		err := client.CallContext(ctx, &receipt, "eth_getTransactionReceipt", tx.Hash())
+		if err == nil && receipt != nil {
+			probe.latency.Observe(probe.now().Sub(sent).Seconds())
+
+			var blocks uint64
+			if receipt.BlockNumber > head {
+				blocks = uint64(receipt.BlockNumber - head)
+			}
+			probe.record(true, true, blocks)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			probe.record(true, false, 0)
+			return fmt.Errorf("%w: %v", errTxNotIncluded, tx.Hash())
+		case <-ticker.C:
+		}
+	}
+}
+
+// send sends a transaction and returns it along with the head block at the
+// time it was sent.
+func (probe *TxProbe) send(ctx context.Context) (*types.Transaction, hexutil.Uint64, error) {
+	client := probe.client()
+
+	// The chain is checked on every probe, as the active endpoint may change.
+	var chainID hexutil.Big
+	if err := client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return nil, 0, err
+	}
+	if id := chainID.ToInt(); !id.IsUint64() || !probe.chainIDs[id.Uint64()] {
+		return nil, 0, fmt.Errorf("%w: %v", errChainNotAllowed, id)
+	}
+
+	// The nonce of the latest block is used rather than the pending one, so
+	// that a transaction stuck in the pool is replaced instead of every
+	// later one queuing behind it.
+	var (
+		nonce    hexutil.Uint64
+		gasPrice hexutil.Big
+		head     hexutil.Uint64
+	)
+	if err := client.CallContext(ctx, &nonce, "eth_getTransactionCount", probe.address, "latest"); err != nil {
+		return nil, 0, err
+	}
+	if err := client.CallContext(ctx, &gasPrice, "eth_gasPrice"); err != nil {
+		return nil, 0, err
+	}
+	if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return nil, 0, err
+	}
+
+	price := gasPrice.ToInt()
+	if probe.lastGasPrice != nil && probe.lastNonce == uint64(nonce) {
+		bumped := new(big.Int).Mul(probe.lastGasPrice, big.NewInt(txProbeReplacementBump))
+		bumped.Div(bumped, big.NewInt(100))
+		if bumped.Cmp(price) > 0 {
+			price = bumped
+		}
+	}
+
+	tx, err := types.SignNewTx(probe.key, types.LatestSignerForChainID(chainID.ToInt()), &types.LegacyTx{
+		Nonce:    uint64(nonce),
+		GasPrice: price,
+		Gas:      txProbeGas,
+		To:       &probe.address,
+		Value:    new(big.Int),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := client.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Bytes(raw)); err != nil {
+		return nil, 0, err
+	}
+
+	probe.lastNonce = uint64(nonce)
+	probe.lastGasPrice = price
+	return tx, head, nil
+}
+
+// record keeps the number of blocks to inclusion of the last included
+// transaction.
+func (probe *TxProbe) record(submitted, included bool, blocks uint64) {
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	probe.probed = true
+	probe.submitted = submitted
+	probe.included = included
+	if included {
+		probe.blocks = blocks
+	}
+}
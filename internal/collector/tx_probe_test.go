@@ -0,0 +1,288 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// simulatedEth is a chain that mines a block on every receipt query and
+// includes transactions two blocks after their submission, unless stalled.
+// Pending transactions can be replaced with ones paying 10% more.
+type simulatedEth struct {
+	t       *testing.T
+	chainID *big.Int
+
+	mu       sync.Mutex
+	stalled  bool
+	head     uint64
+	sent     map[common.Address][]*types.Transaction
+	included map[common.Hash]uint64
+}
+
+func newSimulatedEth(t *testing.T, chainID int64, stalled bool) (*rpc.Client, *simulatedEth) {
+	server := rpc.NewServer()
+	eth := &simulatedEth{
+		t:        t,
+		chainID:  big.NewInt(chainID),
+		stalled:  stalled,
+		head:     100,
+		sent:     make(map[common.Address][]*types.Transaction),
+		included: make(map[common.Hash]uint64),
+	}
+	if err := server.RegisterName("eth", eth); err != nil {
+		t.Fatalf("could not register a service: %#v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	return rpc.DialInProc(server), eth
+}
+
+func (eth *simulatedEth) setStalled(stalled bool) {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+	eth.stalled = stalled
+}
+
+// isIncluded must be called with mu held.
+func (eth *simulatedEth) isIncluded(tx *types.Transaction) bool {
+	block, ok := eth.included[tx.Hash()]
+	return ok && block <= eth.head
+}
+
+func (eth *simulatedEth) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(eth.chainID)
+}
+
+func (eth *simulatedEth) GetTransactionCount(address common.Address, block string) hexutil.Uint64 {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+
+	if block == "pending" {
+		return hexutil.Uint64(len(eth.sent[address]))
+	}
+
+	var nonce uint64
+	for _, tx := range eth.sent[address] {
+		if !eth.isIncluded(tx) {
+			break
+		}
+		nonce++
+	}
+	return hexutil.Uint64(nonce)
+}
+
+func (eth *simulatedEth) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1e9))
+}
+
+func (eth *simulatedEth) BlockNumber() hexutil.Uint64 {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+	return hexutil.Uint64(eth.head)
+}
+
+func (eth *simulatedEth) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(eth.chainID), tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if tx.To() == nil || *tx.To() != sender || tx.Value().Sign() != 0 {
+		eth.t.Errorf("got %v, want a self-transfer of zero value", tx)
+	}
+	sent := eth.sent[sender]
+	switch nonce := tx.Nonce(); {
+	case nonce > uint64(len(sent)):
+		return common.Hash{}, errors.New("nonce too high")
+	case nonce == uint64(len(sent)):
+		eth.sent[sender] = append(sent, tx)
+	case eth.isIncluded(sent[nonce]):
+		return common.Hash{}, errors.New("nonce too low")
+	default:
+		minimum := new(big.Int).Mul(sent[nonce].GasPrice(), big.NewInt(110))
+		if new(big.Int).Mul(tx.GasPrice(), big.NewInt(100)).Cmp(minimum) < 0 {
+			return common.Hash{}, errors.New("replacement transaction underpriced")
+		}
+		delete(eth.included, sent[nonce].Hash())
+		sent[nonce] = tx
+	}
+
+	if !eth.stalled {
+		eth.included[tx.Hash()] = eth.head + 2
+	}
+	return tx.Hash(), nil
+}
+
+func (eth *simulatedEth) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+
+	eth.head++
+	if block, ok := eth.included[hash]; ok && block <= eth.head {
+		return map[string]interface{}{"blockNumber": hexutil.Uint64(block)}, nil
+	}
+	return nil, nil
+}
+
+func newTxProbe(t *testing.T, client *rpc.Client, timeout time.Duration) *TxProbe {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate a key: %#v", err)
+	}
+
+	probe, err := NewTxProbe(func() *rpc.Client { return client }, key, []uint64{1337}, time.Millisecond, timeout)
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	// Every call to now advances the clock by 6 seconds.
+	now := time.Unix(0, 0)
+	probe.now = func() time.Time {
+		now = now.Add(6 * time.Second)
+		return now
+	}
+	return probe
+}
+
+func TestNewTxProbeNoChainsAllowed(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate a key: %#v", err)
+	}
+
+	if _, err := NewTxProbe(nil, key, nil, time.Second, time.Minute); err != errNoChainsAllowed {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestTxProbeProbeChainNotAllowed(t *testing.T) {
+	client, _ := newSimulatedEth(t, 1, false)
+	probe := newTxProbe(t, client, time.Second)
+
+	if err := probe.Probe(context.Background()); !errors.Is(err, errChainNotAllowed) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{
+		"tx_probe_submission_success":      0,
+		"tx_probe_included":                0,
+		"tx_probe_inclusion_blocks":        0,
+		"tx_probe_inclusion_seconds_count": 0,
+		"tx_probe_inclusion_seconds_sum":   0,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTxProbeProbe(t *testing.T) {
+	client, _ := newSimulatedEth(t, 1337, false)
+	probe := newTxProbe(t, client, time.Second)
+
+	for i := 0; i < 2; i++ {
+		if err := probe.Probe(context.Background()); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	want := map[string]float64{
+		"tx_probe_submission_success":      1,
+		"tx_probe_included":                1,
+		"tx_probe_inclusion_blocks":        2,
+		"tx_probe_inclusion_seconds_count": 2,
+		"tx_probe_inclusion_seconds_sum":   12,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTxProbeProbeNotIncluded(t *testing.T) {
+	client, _ := newSimulatedEth(t, 1337, true)
+	probe := newTxProbe(t, client, 50*time.Millisecond)
+
+	if err := probe.Probe(context.Background()); !errors.Is(err, errTxNotIncluded) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{
+		"tx_probe_submission_success":      1,
+		"tx_probe_included":                0,
+		"tx_probe_inclusion_blocks":        0,
+		"tx_probe_inclusion_seconds_count": 0,
+		"tx_probe_inclusion_seconds_sum":   0,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTxProbeProbeReplacesStuckTx(t *testing.T) {
+	client, eth := newSimulatedEth(t, 1337, true)
+	probe := newTxProbe(t, client, 50*time.Millisecond)
+
+	if err := probe.Probe(context.Background()); !errors.Is(err, errTxNotIncluded) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	eth.setStalled(false)
+	if err := probe.Probe(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{
+		"tx_probe_submission_success":      1,
+		"tx_probe_included":                1,
+		"tx_probe_inclusion_blocks":        2,
+		"tx_probe_inclusion_seconds_count": 1,
+		"tx_probe_inclusion_seconds_sum":   6,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	sent := eth.sent[probe.address]
+	if len(sent) != 1 || sent[0].GasPrice().Cmp(big.NewInt(1e9)) <= 0 {
+		t.Fatalf("got %v, want a single transaction replaced with a higher gas price", sent)
+	}
+}
+
+func TestTxProbeProbeError(t *testing.T) {
+	client, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	probe := newTxProbe(t, client, time.Second)
+
+	if err := probe.Probe(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	want := map[string]float64{
+		"tx_probe_submission_success":      0,
+		"tx_probe_included":                0,
+		"tx_probe_inclusion_blocks":        0,
+		"tx_probe_inclusion_seconds_count": 0,
+		"tx_probe_inclusion_seconds_sum":   0,
+	}
+	if got := gather(t, probe); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,299 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// watchBatchSize bounds the number of calls in a single batch request.
+const watchBatchSize = 100
+
+// maxWatchRequestBytes bounds the size of a request to watch a transaction.
+const maxWatchRequestBytes = 1 << 10
+
+var errTooManyWatchedTxs = errors.New("too many watched transactions")
+
+// txStatuses are the states of a watched transaction.
+var txStatuses = []string{"pending", "success", "reverted", "dropped"}
+
+// TxWatcher tracks the receipts of registered transactions on every followed
+// block. Transactions expire once they have the configured number of
+// confirmations, or have been pending or unknown to the node for the
+// configured numbers of blocks.
+type TxWatcher struct {
+	client            follower.ClientFunc
+	expiry            uint64
+	pendingExpiry     uint64
+	droppedExpiry     uint64
+	maxTxs            int
+	statusDesc        *prometheus.Desc
+	confirmationsDesc *prometheus.Desc
+	gasUsedDesc       *prometheus.Desc
+
+	mu   sync.Mutex
+	head uint64
+	txs  map[common.Hash]*watchedTx
+}
+
+type watchedTx struct {
+	status        string
+	confirmations uint64
+	gasUsed       uint64
+	// dropped is the number of consecutive blocks the node did not know
+	// the transaction at.
+	dropped uint64
+	// pending is the number of consecutive blocks the transaction was
+	// pending at.
+	pending uint64
+}
+
+type watchedReceiptResult struct {
+	BlockNumber hexutil.Uint64
+	GasUsed     hexutil.Uint64
+	Status      hexutil.Uint64
+}
+
+// NewTxWatcher returns a watcher of at most maxTxs transactions that expire
+// after expiry confirmations, after pendingExpiry blocks pending or after
+// droppedExpiry blocks dropped.
+func NewTxWatcher(client follower.ClientFunc, expiry, pendingExpiry, droppedExpiry uint64, maxTxs int) *TxWatcher {
+	return &TxWatcher{
+		client:        client,
+		expiry:        expiry,
+		pendingExpiry: pendingExpiry,
+		droppedExpiry: droppedExpiry,
+		maxTxs:        maxTxs,
+		txs:           make(map[common.Hash]*watchedTx),
+		statusDesc: prometheus.NewDesc(
+			"tx_watch_status",
+			"whether the watched transaction is in the status",
+			[]string{"hash", "status"},
+			nil,
+		),
+		confirmationsDesc: prometheus.NewDesc(
+			"tx_watch_confirmations",
+			"number of confirmations of the watched transaction",
+			[]string{"hash"},
+			nil,
+		),
+		gasUsedDesc: prometheus.NewDesc(
+			"tx_watch_gas_used",
+			"gas used by the watched transaction",
+			[]string{"hash"},
+			nil,
+		),
+	}
+}
+
+// Watch registers a transaction. Registering a watched one is a no-op.
+func (watcher *TxWatcher) Watch(hash common.Hash) error {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	if _, ok := watcher.txs[hash]; ok {
+		return nil
+	}
+	if len(watcher.txs) >= watcher.maxTxs {
+		return errTooManyWatchedTxs
+	}
+
+	watcher.txs[hash] = &watchedTx{status: "pending"}
+	return nil
+}
+
+// ServeHTTP registers the transaction of a POST request with a body such as
+// {"hash": "0x..."}.
+func (watcher *TxWatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Hash string
+	}
+	body := http.MaxBytesReader(w, r.Body, maxWatchRequestBytes)
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hash, err := hexutil.Decode(request.Hash)
+	if err != nil || len(hash) != common.HashLength {
+		http.Error(w, "invalid transaction hash", http.StatusBadRequest)
+		return
+	}
+
+	if err := watcher.Watch(common.BytesToHash(hash)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// HandleBlock updates the watched transactions. Transactions that could not
+// be looked up are logged and keep their state, so that other handlers are
+// not held up by this one.
+func (watcher *TxWatcher) HandleBlock(ctx context.Context, block *follower.Block) error {
+	number := uint64(block.Number)
+
+	watcher.mu.Lock()
+	if watcher.head != 0 && number <= watcher.head {
+		watcher.mu.Unlock()
+		return nil
+	}
+	hashes := make([]common.Hash, 0, len(watcher.txs))
+	for hash := range watcher.txs {
+		hashes = append(hashes, hash)
+	}
+	watcher.mu.Unlock()
+
+	// Receipts are fetched without holding the lock, so that registering
+	// transactions and scrapes are not blocked by slow nodes.
+	client := watcher.client()
+	receipts := make([]*watchedReceiptResult, len(hashes))
+	failed := lookupTxs(ctx, client, "eth_getTransactionReceipt", hashes, func(i int) interface{} {
+		return &receipts[i]
+	})
+
+	// Transactions without a receipt are either pending or dropped.
+	var unknown []int
+	for i := range hashes {
+		if !failed[i] && receipts[i] == nil {
+			unknown = append(unknown, i)
+		}
+	}
+	unknownHashes := make([]common.Hash, len(unknown))
+	for j, i := range unknown {
+		unknownHashes[j] = hashes[i]
+	}
+	txs := make([]*struct {
+		Hash common.Hash
+	}, len(unknown))
+	txFailed := lookupTxs(ctx, client, "eth_getTransactionByHash", unknownHashes, func(j int) interface{} {
+		return &txs[j]
+	})
+	known := make([]bool, len(hashes))
+	for j, i := range unknown {
+		failed[i] = txFailed[j]
+		known[i] = txs[j] != nil
+	}
+
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	watcher.head = number
+	for i, hash := range hashes {
+		tx, ok := watcher.txs[hash]
+		if !ok || failed[i] {
+			continue
+		}
+
+		receipt := receipts[i]
+		switch {
+		case receipt != nil:
+			tx.status = "success"
+			if receipt.Status == 0 {
+				tx.status = "reverted"
+			}
+			tx.confirmations = 0
+			if number >= uint64(receipt.BlockNumber) {
+				tx.confirmations = number - uint64(receipt.BlockNumber) + 1
+			}
+			tx.gasUsed = uint64(receipt.GasUsed)
+			tx.dropped = 0
+			tx.pending = 0
+		case known[i]:
+			tx.status = "pending"
+			tx.confirmations = 0
+			tx.dropped = 0
+			tx.pending++
+		default:
+			tx.status = "dropped"
+			tx.confirmations = 0
+			tx.dropped++
+			tx.pending = 0
+		}
+
+		if tx.confirmations >= watcher.expiry || tx.dropped >= watcher.droppedExpiry || tx.pending >= watcher.pendingExpiry {
+			delete(watcher.txs, hash)
+		}
+	}
+
+	return nil
+}
+
+// lookupTxs calls method with every hash in batches, storing results in the
+// value returned by result for the index of the hash. It returns which
+// lookups failed.
+func lookupTxs(ctx context.Context, client *rpc.Client, method string, hashes []common.Hash, result func(i int) interface{}) []bool {
+	failed := make([]bool, len(hashes))
+
+	for start := 0; start < len(hashes); start += watchBatchSize {
+		end := start + watchBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		batch := make([]rpc.BatchElem, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, rpc.BatchElem{Method: method, Args: []interface{}{hashes[i]}, Result: result(i)})
+		}
+
+		if err := client.BatchCallContext(ctx, batch); err != nil {
+			log.Printf("could not look up watched transactions: %v", err)
+			for i := start; i < end; i++ {
+				failed[i] = true
+			}
+			continue
+		}
+		for j, elem := range batch {
+			if elem.Error != nil {
+				log.Printf("could not look up watched transaction %v: %v", hashes[start+j].Hex(), elem.Error)
+				failed[start+j] = true
+			}
+		}
+	}
+
+	return failed
+}
+
+func (watcher *TxWatcher) Describe(ch chan<- *prometheus.Desc) {
+	ch <- watcher.statusDesc
+	ch <- watcher.confirmationsDesc
+	ch <- watcher.gasUsedDesc
+}
+
+func (watcher *TxWatcher) Collect(ch chan<- prometheus.Metric) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	for hash, tx := range watcher.txs {
+		h := hash.Hex()
+		for _, status := range txStatuses {
+			value := 0.0
+			if status == tx.status {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(watcher.statusDesc, prometheus.GaugeValue, value, h, status)
+		}
+
+		value := float64(tx.confirmations)
+		ch <- prometheus.MustNewConstMetric(watcher.confirmationsDesc, prometheus.GaugeValue, value, h)
+
+		if tx.status == "success" || tx.status == "reverted" {
+			value = float64(tx.gasUsed)
+			ch <- prometheus.MustNewConstMetric(watcher.gasUsedDesc, prometheus.GaugeValue, value, h)
+		}
+	}
+}
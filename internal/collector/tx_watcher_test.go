@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/follower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	txSuccess  = common.HexToHash("0x01")
+	txReverted = common.HexToHash("0x02")
+	txPending  = common.HexToHash("0x03")
+	txDropped  = common.HexToHash("0x04")
+	txFailing  = common.HexToHash("0x05")
+)
+
+// newWatchServer serves batches of lookups of a successful and a reverted
+// transaction included in block 100 and a pending one. Lookups of a failing
+// transaction return an error.
+func newWatchServer(t *testing.T) *httptest.Server {
+	receipts := map[common.Hash]string{
+		txSuccess:  `{"blockNumber": "0x64", "gasUsed": "0x5208", "status": "0x1"}`,
+		txReverted: `{"blockNumber": "0x64", "gasUsed": "0x7530", "status": "0x0"}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []struct {
+			ID     json.RawMessage
+			Method string
+			Params []common.Hash
+		}
+		if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+			t.Fatalf("could not decode a batch request: %#v", err)
+		}
+
+		var responses []string
+		for _, request := range requests {
+			result := "null"
+			hash := request.Params[0]
+			switch {
+			case hash == txFailing:
+				responses = append(responses, fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32000, "message": "busy"}}`, request.ID))
+				continue
+			case request.Method == "eth_getTransactionReceipt" && receipts[hash] != "":
+				result = receipts[hash]
+			case request.Method == "eth_getTransactionByHash" && hash == txPending:
+				result = fmt.Sprintf(`{"hash": "%v"}`, hash.Hex())
+			}
+			responses = append(responses, fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, request.ID, result))
+		}
+
+		if _, err := w.Write([]byte("[" + strings.Join(responses, ",") + "]")); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func newTxWatcher(t *testing.T, url string, expiry, pendingExpiry, droppedExpiry uint64, maxTxs int) *TxWatcher {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	return NewTxWatcher(func() *rpc.Client { return client }, expiry, pendingExpiry, droppedExpiry, maxTxs)
+}
+
+// watched returns the nonzero values of gathered watcher metrics with hashes
+// shortened to their last digit, such as "status{1,success}".
+func watched(values map[string]float64) map[string]float64 {
+	short := make(map[string]float64)
+	for name, value := range values {
+		if value != 0 {
+			name = strings.TrimPrefix(name, "tx_watch_")
+			short[watchedHashRE.ReplaceAllString(name, "$1")] = value
+		}
+	}
+	return short
+}
+
+var watchedHashRE = regexp.MustCompile(`0x[0-9a-f]{63}([0-9a-f])`)
+
+func TestTxWatcherHandleBlock(t *testing.T) {
+	rpcServer := newWatchServer(t)
+	defer rpcServer.Close()
+
+	watcher := newTxWatcher(t, rpcServer.URL, 3, 100, 3, 10)
+	for _, hash := range []common.Hash{txSuccess, txReverted, txPending, txDropped, txFailing} {
+		if err := watcher.Watch(hash); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	for _, test := range []struct {
+		block uint64
+		want  map[string]float64
+	}{
+		{
+			block: 101,
+			want: map[string]float64{
+				"status{1,success}": 1, "confirmations{1}": 2, "gas_used{1}": 21000,
+				"status{2,reverted}": 1, "confirmations{2}": 2, "gas_used{2}": 30000,
+				"status{3,pending}": 1,
+				"status{4,dropped}": 1,
+			},
+		},
+		// Already handled blocks are ignored.
+		{
+			block: 100,
+			want: map[string]float64{
+				"status{1,success}": 1, "confirmations{1}": 2, "gas_used{1}": 21000,
+				"status{2,reverted}": 1, "confirmations{2}": 2, "gas_used{2}": 30000,
+				"status{3,pending}": 1,
+				"status{4,dropped}": 1,
+			},
+		},
+		{
+			block: 102,
+			want: map[string]float64{
+				"status{3,pending}": 1,
+				"status{4,dropped}": 1,
+			},
+		},
+		{
+			block: 103,
+			want: map[string]float64{
+				"status{3,pending}": 1,
+			},
+		},
+	} {
+		if err := watcher.HandleBlock(context.Background(), &follower.Block{Number: hexutil.Uint64(test.block)}); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+		// A transaction that could not be looked up keeps its state.
+		test.want["status{5,pending}"] = 1
+		if got := watched(gather(t, watcher)); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Fatalf("got %v, want %v at block %v", got, test.want, test.block)
+		}
+	}
+}
+
+func TestTxWatcherHandleBlockExpiry(t *testing.T) {
+	rpcServer := newWatchServer(t)
+	defer rpcServer.Close()
+
+	// Pending and dropped transactions expire independently of confirmations.
+	for _, test := range []struct {
+		hash          common.Hash
+		pendingExpiry uint64
+		droppedExpiry uint64
+	}{
+		{txPending, 2, 100},
+		{txDropped, 100, 2},
+	} {
+		watcher := newTxWatcher(t, rpcServer.URL, 100, test.pendingExpiry, test.droppedExpiry, 10)
+		if err := watcher.Watch(test.hash); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		for block, want := range []int{1, 0} {
+			if err := watcher.HandleBlock(context.Background(), &follower.Block{Number: hexutil.Uint64(block + 1)}); err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if got := len(watched(gather(t, watcher))); got != want {
+				t.Fatalf("got %v, want %v at block %v for %v", got, want, block+1, test.hash)
+			}
+		}
+	}
+}
+
+func TestTxWatcherHandleBlockError(t *testing.T) {
+	watcher := newTxWatcher(t, "http://localhost", 3, 100, 3, 10)
+	if err := watcher.Watch(txPending); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	// Failed lookups do not hold up other handlers of the follower.
+	if err := watcher.HandleBlock(context.Background(), &follower.Block{Number: 1}); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := map[string]float64{"status{3,pending}": 1}
+	if got := watched(gather(t, watcher)); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTxWatcherServeHTTP(t *testing.T) {
+	watcher := newTxWatcher(t, "http://localhost", 3, 100, 3, 1)
+
+	for _, test := range []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, `{"hash": "0x01"}`, http.StatusBadRequest},
+		{http.MethodPost, `not json`, http.StatusBadRequest},
+		{http.MethodPost, fmt.Sprintf(`{"hash": "%v", "padding": "%v"}`, txPending.Hex(), strings.Repeat("0", 2000)), http.StatusBadRequest},
+		{http.MethodPost, fmt.Sprintf(`{"hash": "%v"}`, txPending.Hex()), http.StatusAccepted},
+		{http.MethodPost, fmt.Sprintf(`{"hash": "%v"}`, txPending.Hex()), http.StatusAccepted},
+		{http.MethodPost, fmt.Sprintf(`{"hash": "%v"}`, txDropped.Hex()), http.StatusTooManyRequests},
+	} {
+		w := httptest.NewRecorder()
+		watcher.ServeHTTP(w, httptest.NewRequest(test.method, "/watch/tx", strings.NewReader(test.body)))
+		if got := w.Code; got != test.want {
+			t.Fatalf("got %v, want %v for %v %v", got, test.want, test.method, test.body)
+		}
+	}
+
+	want := map[string]float64{"status{3,pending}": 1}
+	if got := watched(gather(t, watcher)); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
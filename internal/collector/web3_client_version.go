@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client identifies an Ethereum client implementation.
+type Client string
+
+const (
+	ClientUnknown      Client = "unknown"
+	ClientGeth         Client = "geth"
+	ClientQuorum       Client = "quorum"
+	ClientBesu         Client = "besu"
+	ClientNethermind   Client = "nethermind"
+	ClientErigon       Client = "erigon"
+	ClientOpenEthereum Client = "openethereum"
+)
+
+// DetectClient identifies the client from its web3_clientVersion.
+func DetectClient(rpc *rpc.Client) (Client, error) {
+	var version string
+	if err := rpc.Call(&version, "web3_clientVersion"); err != nil {
+		return ClientUnknown, err
+	}
+
+	return parseClientVersion(version), nil
+}
+
+// parseClientVersion identifies the client from versions such as
+// "Geth/v1.10.23-stable(quorum-v22.7.1)/linux-amd64/go1.18.5".
+func parseClientVersion(version string) Client {
+	version = strings.ToLower(version)
+	name, _, _ := strings.Cut(version, "/")
+
+	switch {
+	case strings.Contains(version, "quorum"):
+		return ClientQuorum
+	case name == "geth":
+		return ClientGeth
+	case name == "besu":
+		return ClientBesu
+	case name == "nethermind":
+		return ClientNethermind
+	case name == "erigon":
+		return ClientErigon
+	case name == "openethereum", name == "parity-ethereum", name == "parity":
+		return ClientOpenEthereum
+	}
+
+	return ClientUnknown
+}
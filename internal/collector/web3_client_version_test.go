@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestDetectClientError(t *testing.T) {
+	rpc, err := rpc.DialHTTP("http://localhost")
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	client, err := DetectClient(rpc)
+	if _, ok := err.(*url.Error); !ok {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if client != ClientUnknown {
+		t.Fatalf("got %v, want %v", client, ClientUnknown)
+	}
+}
+
+func TestDetectClient(t *testing.T) {
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"result": "besu/v23.4.0/linux-x86_64/openjdk-java-17"}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer rpcServer.Close()
+
+	rpc, err := rpc.DialHTTP(rpcServer.URL)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+
+	client, err := DetectClient(rpc)
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if client != ClientBesu {
+		t.Fatalf("got %v, want %v", client, ClientBesu)
+	}
+}
+
+func TestParseClientVersion(t *testing.T) {
+	for version, want := range map[string]Client{
+		"Geth/v1.13.5-stable-916d6a44/linux-amd64/go1.21.4":                    ClientGeth,
+		"Geth/v1.10.23-stable(quorum-v22.7.1)/linux-amd64/go1.18.5":            ClientQuorum,
+		"besu/v23.4.0/linux-x86_64/openjdk-java-17":                            ClientBesu,
+		"Nethermind/v1.20.3+f3b4e5a9/linux-x64/dotnet7.0.8":                    ClientNethermind,
+		"erigon/2.48.1/linux-amd64/go1.20.5":                                   ClientErigon,
+		"OpenEthereum//v3.3.5-stable/x86_64-linux-musl/rustc1.59.0":            ClientOpenEthereum,
+		"Parity-Ethereum//v2.7.2-stable-2662d19-20200206/x86_64-unknown-linux": ClientOpenEthereum,
+		"reth/v0.1.0": ClientUnknown,
+	} {
+		if got := parseClientVersion(version); got != want {
+			t.Fatalf("got %v for %v, want %v", got, version, want)
+		}
+	}
+}
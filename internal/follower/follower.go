@@ -0,0 +1,197 @@
+package follower
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxBlocksPerPoll bounds the number of blocks processed in a single poll so
+// that catching up after a long outage does not starve other work.
+const maxBlocksPerPoll = 128
+
+var errBlockNotFound = errors.New("block not found")
+
+// Block is a block with full transactions as returned by
+// eth_getBlockByNumber. Only the fields used by handlers are decoded.
+type Block struct {
+	Number        hexutil.Uint64
+	Hash          common.Hash
+	ParentHash    common.Hash
+	Timestamp     hexutil.Uint64
+	Miner         common.Address
+	ExtraData     hexutil.Bytes
+	GasUsed       hexutil.Uint64
+	BaseFeePerGas *hexutil.Big
+	BlobGasUsed   *hexutil.Uint64
+	ExcessBlobGas *hexutil.Uint64
+	RequestsHash  *common.Hash
+	Transactions  []Transaction
+	Withdrawals   []Withdrawal
+}
+
+type Transaction struct {
+	Hash                 common.Hash
+	Type                 hexutil.Uint64
+	To                   *common.Address
+	Value                hexutil.Big
+	GasPrice             *hexutil.Big
+	MaxPriorityFeePerGas *hexutil.Big
+	MaxFeePerGas         *hexutil.Big
+}
+
+type Withdrawal struct {
+	Index          hexutil.Uint64
+	ValidatorIndex hexutil.Uint64
+	Address        common.Address
+	Amount         hexutil.Uint64
+}
+
+// Handler processes blocks in ascending order of their numbers. When a
+// handler fails, the block is passed to every handler again on the next poll,
+// so handlers must ignore blocks they have already processed.
+type Handler interface {
+	HandleBlock(ctx context.Context, block *Block) error
+}
+
+// ClientFunc returns the client to poll. It allows following the active
+// endpoint of an upstream pool.
+type ClientFunc func() *rpc.Client
+
+// Follower polls the chain head and passes every new block to its handlers.
+// Blocks are passed once they have the given number of confirmations, which
+// keeps blocks replaced by shallower reorgs away from handlers. Deeper reorgs
+// are detected by the parent hash of the next block and counted.
+type Follower struct {
+	client        ClientFunc
+	interval      time.Duration
+	confirmations uint64
+	reorgsDesc    *prometheus.Desc
+	reorgs        atomic.Uint64
+
+	mu       sync.Mutex
+	handlers []Handler
+	next     uint64
+	last     common.Hash
+}
+
+func New(client ClientFunc, interval time.Duration, confirmations uint64) *Follower {
+	return &Follower{
+		client:        client,
+		interval:      interval,
+		confirmations: confirmations,
+		reorgsDesc: prometheus.NewDesc(
+			"ethereum_exporter_follower_reorgs_total",
+			"number of reorgs detected in followed blocks",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (follower *Follower) Describe(ch chan<- *prometheus.Desc) {
+	ch <- follower.reorgsDesc
+}
+
+func (follower *Follower) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(follower.reorgsDesc, prometheus.CounterValue, float64(follower.reorgs.Load()))
+}
+
+// Subscribe adds a handler that is called for every new block.
+func (follower *Follower) Subscribe(handler Handler) {
+	follower.mu.Lock()
+	defer follower.mu.Unlock()
+
+	follower.handlers = append(follower.handlers, handler)
+}
+
+// StartAt makes the follower process blocks starting at number instead of
+// the chain head. Catching up is bounded by maxBlocksPerPoll blocks per poll.
+func (follower *Follower) StartAt(number uint64) {
+	follower.mu.Lock()
+	defer follower.mu.Unlock()
+
+	if follower.next == 0 || number < follower.next {
+		follower.next = number
+		follower.last = common.Hash{}
+	}
+}
+
+// Run polls the chain every interval until ctx is done.
+func (follower *Follower) Run(ctx context.Context) {
+	ticker := time.NewTicker(follower.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := follower.Poll(ctx); err != nil {
+			log.Printf("follower: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll processes blocks from the last processed one up to the chain head.
+func (follower *Follower) Poll(ctx context.Context) error {
+	follower.mu.Lock()
+	defer follower.mu.Unlock()
+
+	client := follower.client()
+
+	var head hexutil.Uint64
+	if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return err
+	}
+
+	if uint64(head) < follower.confirmations {
+		return nil
+	}
+	confirmed := uint64(head) - follower.confirmations
+
+	if follower.next == 0 {
+		follower.next = confirmed
+	}
+
+	for processed := 0; follower.next <= confirmed && processed < maxBlocksPerPoll; processed++ {
+		var block *Block
+		err := client.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(follower.next), true)
+		if err == nil && block == nil {
+			err = errBlockNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Blocks already passed to handlers cannot be taken back, so a
+		// replaced parent is only reported.
+		if follower.last != (common.Hash{}) && block.ParentHash != follower.last {
+			follower.reorgs.Add(1)
+			log.Printf("follower: reorg detected at block %d, parent %v replaced by %v", block.Number, follower.last, block.ParentHash)
+			// A retry of the block after a handler error is not counted again.
+			follower.last = block.ParentHash
+		}
+
+		for _, handler := range follower.handlers {
+			if err := handler.HandleBlock(ctx, block); err != nil {
+				return err
+			}
+		}
+
+		follower.next++
+		follower.last = block.Hash
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// blockHash returns the hash of a block, which differs for blocks at or after
+// fork if it is set.
+func blockHash(number, fork uint64) string {
+	if fork != 0 && number >= fork {
+		return fmt.Sprintf("0x%064x", number<<32|1)
+	}
+	return fmt.Sprintf("0x%064x", number<<32)
+}
+
+// newChainServer serves a chain up to head. Blocks at or after fork are
+// replaced by a reorg if it is set.
+func newChainServer(t *testing.T, head, fork *atomic.Uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage
+			Method string
+			Params []interface{}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("could not decode a request: %#v", err)
+		}
+
+		var result string
+		switch request.Method {
+		case "eth_blockNumber":
+			result = fmt.Sprintf(`"%s"`, hexutil.EncodeUint64(head.Load()))
+		case "eth_getBlockByNumber":
+			number, err := hexutil.DecodeUint64(request.Params[0].(string))
+			if err != nil {
+				t.Fatalf("invalid block number: %#v", err)
+			}
+			result = fmt.Sprintf(`{"number": "%s", "hash": "%s", "parentHash": "%s", "transactions": []}`,
+				hexutil.EncodeUint64(number), blockHash(number, fork.Load()), blockHash(number-1, fork.Load()))
+		}
+
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, request.ID, result)
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+type recorder struct {
+	numbers []uint64
+	fail    bool
+}
+
+func (r *recorder) HandleBlock(ctx context.Context, block *Block) error {
+	if r.fail {
+		return errors.New("failed")
+	}
+	r.numbers = append(r.numbers, uint64(block.Number))
+	return nil
+}
+
+func newFollower(t *testing.T, url string, confirmations uint64) *Follower {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	return New(func() *rpc.Client { return client }, 0, confirmations)
+}
+
+func TestFollowerPoll(t *testing.T) {
+	var head atomic.Uint64
+	head.Store(10)
+
+	server := newChainServer(t, &head, new(atomic.Uint64))
+	defer server.Close()
+
+	follower := newFollower(t, server.URL, 0)
+	handler := &recorder{}
+	follower.Subscribe(handler)
+
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	head.Store(13)
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if got := fmt.Sprint(handler.numbers); got != "[10 11 12 13]" {
+		t.Fatalf("got %v, want [10 11 12 13]", got)
+	}
+}
+
+func TestFollowerStartAt(t *testing.T) {
+	var head atomic.Uint64
+	head.Store(maxBlocksPerPoll + 10)
+
+	server := newChainServer(t, &head, new(atomic.Uint64))
+	defer server.Close()
+
+	follower := newFollower(t, server.URL, 0)
+	handler := &recorder{}
+	follower.Subscribe(handler)
+	follower.StartAt(5)
+
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := len(handler.numbers); got != maxBlocksPerPoll {
+		t.Fatalf("got %v blocks, want %v", got, maxBlocksPerPoll)
+	}
+	if got := handler.numbers[0]; got != 5 {
+		t.Fatalf("got %v, want 5", got)
+	}
+
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := handler.numbers[len(handler.numbers)-1]; got != head.Load() {
+		t.Fatalf("got %v, want %v", got, head.Load())
+	}
+}
+
+func TestFollowerPollHandlerError(t *testing.T) {
+	var head atomic.Uint64
+	head.Store(10)
+
+	server := newChainServer(t, &head, new(atomic.Uint64))
+	defer server.Close()
+
+	follower := newFollower(t, server.URL, 0)
+	handler := &recorder{fail: true}
+	follower.Subscribe(handler)
+
+	if err := follower.Poll(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	handler.fail = false
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := fmt.Sprint(handler.numbers); got != "[10]" {
+		t.Fatalf("got %v, want [10]", got)
+	}
+}
+
+func TestFollowerPollConfirmations(t *testing.T) {
+	var head atomic.Uint64
+	head.Store(10)
+
+	server := newChainServer(t, &head, new(atomic.Uint64))
+	defer server.Close()
+
+	follower := newFollower(t, server.URL, 2)
+	handler := &recorder{}
+	follower.Subscribe(handler)
+
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	head.Store(12)
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if got := fmt.Sprint(handler.numbers); got != "[8 9 10]" {
+		t.Fatalf("got %v, want [8 9 10]", got)
+	}
+}
+
+func TestFollowerPollReorg(t *testing.T) {
+	var head, fork atomic.Uint64
+	head.Store(10)
+
+	server := newChainServer(t, &head, &fork)
+	defer server.Close()
+
+	follower := newFollower(t, server.URL, 0)
+	handler := &recorder{}
+	follower.Subscribe(handler)
+
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	// Block 10 is replaced before block 11 is followed.
+	fork.Store(10)
+	head.Store(12)
+	handler.fail = true
+	if err := follower.Poll(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+	handler.fail = false
+	if err := follower.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if got := fmt.Sprint(handler.numbers); got != "[10 11 12]" {
+		t.Fatalf("got %v, want [10 11 12]", got)
+	}
+
+	// The retry after the handler error is not counted again.
+	ch := make(chan prometheus.Metric, 1)
+	follower.Collect(ch)
+	close(ch)
+
+	var metric dto.Metric
+	if err := (<-ch).Write(&metric); err != nil {
+		t.Fatalf("expected metric, got %#v", err)
+	}
+	if got := metric.Counter.GetValue(); got != 1 {
+		t.Fatalf("got %v reorgs, want 1", got)
+	}
+}
@@ -0,0 +1,369 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/http2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// otlpGRPCPath is the gRPC method of the OTLP metrics service.
+const otlpGRPCPath = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// otlpScope is the instrumentation scope of exported metrics.
+const otlpScope = "github.com/31z4/ethereum-prometheus-exporter"
+
+// ResourceFunc returns the attributes of the resource metrics are exported
+// for, such as those of the active node. Attributes returned along with an
+// error are only used until attributes are returned without one.
+type ResourceFunc func(ctx context.Context) (map[string]string, error)
+
+// OTLP exports batches to an OpenTelemetry collector. Gauges and untyped
+// metrics become gauges, counters become monotonic cumulative sums, and
+// histograms and summaries keep their type.
+type OTLP struct {
+	url      string
+	grpc     bool
+	doer     *headerDoer
+	resource ResourceFunc
+	start    time.Time
+
+	// attributes are the last detected resource attributes, reused while
+	// detection fails.
+	attributes map[string]string
+	detected   bool
+}
+
+// NewOTLP returns a target exporting to the OTLP/HTTP endpoint at url, such
+// as http://collector:4318/v1/metrics, or to the OTLP/gRPC one, such as
+// http://collector:4317, if grpc is set. The resource attributes are
+// detected on every export.
+func NewOTLP(url string, grpc bool, timeout time.Duration, headers http.Header, resource ResourceFunc) *OTLP {
+	client := &http.Client{Timeout: timeout}
+	if grpc {
+		transport := &http2.Transport{}
+		if strings.HasPrefix(url, "http://") {
+			// gRPC without TLS uses HTTP/2 with prior knowledge.
+			transport.AllowHTTP = true
+			transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			}
+		}
+		client.Transport = transport
+	}
+
+	return &OTLP{
+		url:      url,
+		grpc:     grpc,
+		doer:     &headerDoer{client: client, headers: headers},
+		resource: resource,
+		start:    time.Now(),
+	}
+}
+
+func (otlp *OTLP) Push(ctx context.Context, batches []Batch) error {
+	attributes, err := otlp.resource(ctx)
+	if err != nil {
+		log.Printf("could not detect resource attributes: %v", err)
+	}
+	if err == nil || !otlp.detected {
+		otlp.attributes = attributes
+		otlp.detected = err == nil
+	}
+
+	body := encodeExportRequest(otlp.attributes, otlp.start, batches)
+	if otlp.grpc {
+		return otlp.pushGRPC(ctx, body)
+	}
+	return otlp.pushHTTP(ctx, body)
+}
+
+func (otlp *OTLP) pushHTTP(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, otlp.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := otlp.doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("unexpected status code %d while exporting to %s: %s", resp.StatusCode, otlp.url, bytes.TrimSpace(message))
+
+	// Client errors other than rate limiting are not retried, as the same
+	// request would be rejected again.
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// grpcRetryable are the gRPC status codes the OTLP specification allows to
+// retry on.
+var grpcRetryable = map[string]bool{
+	"1":  true, // CANCELLED
+	"4":  true, // DEADLINE_EXCEEDED
+	"8":  true, // RESOURCE_EXHAUSTED
+	"10": true, // ABORTED
+	"11": true, // OUT_OF_RANGE
+	"14": true, // UNAVAILABLE
+	"15": true, // DATA_LOSS
+}
+
+func (otlp *OTLP) pushGRPC(ctx context.Context, body []byte) error {
+	// A gRPC message is prefixed with an uncompressed flag and its length.
+	message := make([]byte, 5, 5+len(body))
+	binary.BigEndian.PutUint32(message[1:], uint32(len(body)))
+	message = append(message, body...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(otlp.url, "/")+otlpGRPCPath, bytes.NewReader(message))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := otlp.doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The status is in the trailers, which are only read with the body.
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d while exporting to %s", resp.StatusCode, otlp.url)
+	}
+
+	status := resp.Trailer.Get("Grpc-Status")
+	text := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		// Responses without a message carry the status in the headers.
+		status = resp.Header.Get("Grpc-Status")
+		text = resp.Header.Get("Grpc-Message")
+	}
+	if status == "0" {
+		return nil
+	}
+
+	err = fmt.Errorf("unexpected gRPC status %s while exporting to %s: %s", status, otlp.url, text)
+	if !grpcRetryable[status] {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// otlpMetric is a metric with the data points of every batch.
+type otlpMetric struct {
+	name   string
+	help   string
+	typ    dto.MetricType
+	points [][]byte
+}
+
+// encodeExportRequest encodes an ExportMetricsServiceRequest protobuf
+// message with a single resource and scope, in which every metric family
+// becomes a metric with a data point per metric and batch.
+func encodeExportRequest(attributes map[string]string, start time.Time, batches []Batch) []byte {
+	var metrics []*otlpMetric
+	index := make(map[string]*otlpMetric)
+
+	for _, batch := range batches {
+		for _, family := range batch.Families {
+			metric, ok := index[family.GetName()]
+			if !ok {
+				metric = &otlpMetric{name: family.GetName(), help: family.GetHelp(), typ: family.GetType()}
+				index[metric.name] = metric
+				metrics = append(metrics, metric)
+			}
+
+			for _, m := range family.Metric {
+				timestamp := batch.Time
+				if m.TimestampMs != nil {
+					timestamp = time.UnixMilli(m.GetTimestampMs())
+				}
+				metric.points = append(metric.points, encodeDataPoint(m, start, timestamp))
+			}
+		}
+	}
+
+	var scope []byte
+	scope = protowire.AppendTag(scope, 1, protowire.BytesType)
+	scope = protowire.AppendString(scope, otlpScope)
+
+	var scopeMetrics []byte
+	scopeMetrics = appendMessage(scopeMetrics, 1, scope)
+	for _, metric := range metrics {
+		scopeMetrics = appendMessage(scopeMetrics, 2, encodeMetric(metric))
+	}
+
+	// Attributes are sorted so that identical resources encode identically.
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var resource []byte
+	for _, name := range names {
+		resource = appendMessage(resource, 1, encodeKeyValue(name, attributes[name]))
+	}
+
+	var resourceMetrics []byte
+	resourceMetrics = appendMessage(resourceMetrics, 1, resource)
+	resourceMetrics = appendMessage(resourceMetrics, 2, scopeMetrics)
+
+	return appendMessage(nil, 1, resourceMetrics)
+}
+
+func encodeMetric(metric *otlpMetric) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, metric.name)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, metric.help)
+
+	var data []byte
+	for _, point := range metric.points {
+		data = appendMessage(data, 1, point)
+	}
+
+	switch metric.typ {
+	case dto.MetricType_COUNTER:
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, 2) // AGGREGATION_TEMPORALITY_CUMULATIVE
+		data = protowire.AppendTag(data, 3, protowire.VarintType)
+		data = protowire.AppendVarint(data, 1) // is_monotonic
+		return appendMessage(b, 7, data)
+	case dto.MetricType_HISTOGRAM:
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, 2) // AGGREGATION_TEMPORALITY_CUMULATIVE
+		return appendMessage(b, 9, data)
+	case dto.MetricType_SUMMARY:
+		return appendMessage(b, 11, data)
+	default:
+		return appendMessage(b, 5, data)
+	}
+}
+
+// encodeDataPoint encodes a NumberDataPoint, HistogramDataPoint or
+// SummaryDataPoint depending on the type of metric.
+func encodeDataPoint(metric *dto.Metric, start, timestamp time.Time) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 2, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(start.UnixNano()))
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(timestamp.UnixNano()))
+
+	var attributes [][]byte
+	for _, pair := range metric.Label {
+		attributes = append(attributes, encodeKeyValue(pair.GetName(), pair.GetValue()))
+	}
+
+	switch {
+	case metric.Histogram != nil:
+		h := metric.Histogram
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, h.GetSampleCount())
+		b = protowire.AppendTag(b, 5, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(h.GetSampleSum()))
+
+		// OTLP buckets are not cumulative and the +Inf bound is implicit.
+		var counts, bounds []byte
+		var previous uint64
+		for _, bucket := range h.Bucket {
+			if math.IsInf(bucket.GetUpperBound(), 1) {
+				break
+			}
+			counts = protowire.AppendFixed64(counts, bucket.GetCumulativeCount()-previous)
+			bounds = protowire.AppendFixed64(bounds, math.Float64bits(bucket.GetUpperBound()))
+			previous = bucket.GetCumulativeCount()
+		}
+		counts = protowire.AppendFixed64(counts, h.GetSampleCount()-previous)
+
+		b = protowire.AppendTag(b, 6, protowire.BytesType)
+		b = protowire.AppendBytes(b, counts)
+		if len(bounds) > 0 {
+			b = protowire.AppendTag(b, 7, protowire.BytesType)
+			b = protowire.AppendBytes(b, bounds)
+		}
+		return appendAttributes(b, 9, attributes)
+	case metric.Summary != nil:
+		s := metric.Summary
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, s.GetSampleCount())
+		b = protowire.AppendTag(b, 5, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(s.GetSampleSum()))
+		for _, quantile := range s.Quantile {
+			var q []byte
+			q = protowire.AppendTag(q, 1, protowire.Fixed64Type)
+			q = protowire.AppendFixed64(q, math.Float64bits(quantile.GetQuantile()))
+			q = protowire.AppendTag(q, 2, protowire.Fixed64Type)
+			q = protowire.AppendFixed64(q, math.Float64bits(quantile.GetValue()))
+			b = appendMessage(b, 6, q)
+		}
+		return appendAttributes(b, 7, attributes)
+	}
+
+	var value float64
+	switch {
+	case metric.Counter != nil:
+		value = metric.Counter.GetValue()
+	case metric.Gauge != nil:
+		value = metric.Gauge.GetValue()
+	case metric.Untyped != nil:
+		value = metric.Untyped.GetValue()
+	}
+	b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(value))
+	return appendAttributes(b, 7, attributes)
+}
+
+// encodeKeyValue encodes a KeyValue with a string value.
+func encodeKeyValue(key, value string) []byte {
+	var anyValue []byte
+	anyValue = protowire.AppendTag(anyValue, 1, protowire.BytesType)
+	anyValue = protowire.AppendString(anyValue, value)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, key)
+	return appendMessage(b, 2, anyValue)
+}
+
+// appendAttributes appends encoded KeyValue messages as the field num.
+func appendAttributes(b []byte, num protowire.Number, attributes [][]byte) []byte {
+	for _, attribute := range attributes {
+		b = appendMessage(b, num, attribute)
+	}
+	return b
+}
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
@@ -0,0 +1,276 @@
+package push
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// protoFields decodes the fields of a message by number. Values of length
+// delimited fields are unwrapped, others are kept as encoded.
+func protoFields(t *testing.T, data []byte) map[protowire.Number][][]byte {
+	fields := make(map[protowire.Number][][]byte)
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			t.Fatalf("could not decode a tag: %v", protowire.ParseError(n))
+		}
+		data = data[n:]
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			t.Fatalf("could not decode a field: %v", protowire.ParseError(n))
+		}
+		value := data[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		fields[num] = append(fields[num], value)
+		data = data[n:]
+	}
+	return fields
+}
+
+func protoDouble(value []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(value))
+}
+
+// decodeKeyValues decodes KeyValue messages with string values into
+// `key=value` pairs.
+func decodeKeyValues(t *testing.T, values [][]byte) string {
+	var pairs []string
+	for _, encoded := range values {
+		kv := protoFields(t, encoded)
+		value := protoFields(t, kv[2][0])
+		pairs = append(pairs, string(kv[1][0])+"="+string(value[1][0]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// decodeExportRequest decodes an ExportMetricsServiceRequest into its
+// resource attributes and lines such as `gauge name{key=value} 1@1000` for
+// every data point.
+func decodeExportRequest(t *testing.T, data []byte) (string, []string) {
+	resourceMetrics := protoFields(t, protoFields(t, data)[1][0])
+	resource := decodeKeyValues(t, protoFields(t, resourceMetrics[1][0])[1])
+
+	var lines []string
+	for _, metric := range protoFields(t, resourceMetrics[2][0])[2] {
+		fields := protoFields(t, metric)
+		name := string(fields[1][0])
+
+		for kind, num := range map[string]protowire.Number{"gauge": 5, "sum": 7, "histogram": 9, "summary": 11} {
+			if fields[num] == nil {
+				continue
+			}
+			data := protoFields(t, fields[num][0])
+			if kind == "sum" {
+				temporality, _ := protowire.ConsumeVarint(data[2][0])
+				monotonic, _ := protowire.ConsumeVarint(data[3][0])
+				kind = fmt.Sprintf("sum/%v/%v", temporality, monotonic)
+			}
+
+			for _, point := range data[1] {
+				p := protoFields(t, point)
+				timestamp := binary.LittleEndian.Uint64(p[3][0]) / uint64(time.Millisecond)
+
+				var value string
+				var attributes [][]byte
+				switch kind {
+				case "histogram":
+					var counts []string
+					for b := p[6][0]; len(b) > 0; b = b[8:] {
+						counts = append(counts, fmt.Sprint(binary.LittleEndian.Uint64(b)))
+					}
+					var bounds []string
+					for b := p[7][0]; len(b) > 0; b = b[8:] {
+						bounds = append(bounds, fmt.Sprint(protoDouble(b)))
+					}
+					value = fmt.Sprintf("%v/%v/%v/%v", binary.LittleEndian.Uint64(p[4][0]), protoDouble(p[5][0]), strings.Join(counts, ":"), strings.Join(bounds, ":"))
+					attributes = p[9]
+				default:
+					value = fmt.Sprint(protoDouble(p[4][0]))
+					attributes = p[7]
+				}
+
+				lines = append(lines, fmt.Sprintf("%s %s{%s} %s@%d", kind, name, decodeKeyValues(t, attributes), value, timestamp))
+			}
+		}
+	}
+	return resource, lines
+}
+
+func testBatches() []Batch {
+	counter := &dto.MetricFamily{
+		Name: proto.String("follower_blocks_total"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Metric: []*dto.Metric{{
+			Label:   []*dto.LabelPair{{Name: proto.String("chain"), Value: proto.String("mainnet")}},
+			Counter: &dto.Counter{Value: proto.Float64(7)},
+		}},
+	}
+	histogram := &dto.MetricFamily{
+		Name: proto.String("latency"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{
+			Histogram: &dto.Histogram{
+				SampleCount: proto.Uint64(3),
+				SampleSum:   proto.Float64(4.5),
+				Bucket: []*dto.Bucket{
+					{UpperBound: proto.Float64(0.5), CumulativeCount: proto.Uint64(1)},
+					{UpperBound: proto.Float64(2), CumulativeCount: proto.Uint64(2)},
+				},
+			},
+		}},
+	}
+
+	return []Batch{
+		{Time: time.UnixMilli(1000), Families: []*dto.MetricFamily{gaugeFamily("eth_block_number", 100)}},
+		{Time: time.UnixMilli(2000), Families: []*dto.MetricFamily{gaugeFamily("eth_block_number", 101), counter, histogram}},
+	}
+}
+
+func TestOTLPPushHTTP(t *testing.T) {
+	var resources []string
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-protobuf" {
+			t.Errorf("got %v, want application/x-protobuf", got)
+		}
+		if got := r.Header.Get("Api-Key"); got != "secret" {
+			t.Errorf("got %v, want secret", got)
+		}
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("could not read a request: %#v", err)
+		}
+		var resource string
+		resource, got = decodeExportRequest(t, data)
+		resources = append(resources, resource)
+	}))
+	defer server.Close()
+
+	// Attributes returned with an error are used until they are detected,
+	// and the detected ones are reused afterwards.
+	calls := 0
+	resource := func(ctx context.Context) (map[string]string, error) {
+		calls++
+		switch calls {
+		case 1:
+			return map[string]string{"service.name": "ethereum_exporter"}, errors.New("node unavailable")
+		case 2:
+			return map[string]string{"service.name": "ethereum_exporter", "ethereum.chain_id": "1"}, nil
+		default:
+			return map[string]string{"service.name": "ethereum_exporter"}, errors.New("node unavailable")
+		}
+	}
+
+	headers := http.Header{"Api-Key": []string{"secret"}}
+	otlp := NewOTLP(server.URL+"/v1/metrics", false, time.Second, headers, resource)
+	otlp.start = time.UnixMilli(500)
+
+	for i := 0; i < 3; i++ {
+		if err := otlp.Push(context.Background(), testBatches()); err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	want := "[service.name=ethereum_exporter ethereum.chain_id=1,service.name=ethereum_exporter ethereum.chain_id=1,service.name=ethereum_exporter]"
+	if fmt.Sprint(resources) != want {
+		t.Fatalf("got %v, want %v", resources, want)
+	}
+
+	lines := []string{
+		"gauge eth_block_number{} 100@1000",
+		"gauge eth_block_number{} 101@2000",
+		"sum/2/1 follower_blocks_total{chain=mainnet} 7@2000",
+		"histogram latency{} 3/4.5/1:1:1/0.5:2@2000",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(lines) {
+		t.Fatalf("got %v, want %v", got, lines)
+	}
+}
+
+func TestOTLPPushHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	resource := func(ctx context.Context) (map[string]string, error) { return nil, nil }
+	otlp := NewOTLP(server.URL, false, time.Second, nil, resource)
+
+	var permanent *permanentError
+	if err := otlp.Push(context.Background(), testBatches()); !errors.As(err, &permanent) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestOTLPPushGRPC(t *testing.T) {
+	for _, test := range []struct {
+		status    string
+		err       bool
+		permanent bool
+	}{
+		{status: "0"},
+		{status: "14", err: true},
+		{status: "3", err: true, permanent: true},
+	} {
+		var got []string
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor != 2 {
+				t.Errorf("got HTTP/%v, want HTTP/2", r.ProtoMajor)
+			}
+			if r.URL.Path != otlpGRPCPath {
+				t.Errorf("got %v, want %v", r.URL.Path, otlpGRPCPath)
+			}
+			if got := r.Header.Get("Content-Type"); got != "application/grpc" {
+				t.Errorf("got %v, want application/grpc", got)
+			}
+
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatalf("could not read a request: %#v", err)
+			}
+			if length := binary.BigEndian.Uint32(data[1:5]); data[0] != 0 || int(length) != len(data)-5 {
+				t.Fatalf("invalid gRPC message prefix %v", data[:5])
+			}
+			_, got = decodeExportRequest(t, data[5:])
+
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set(http.TrailerPrefix+"Grpc-Status", test.status)
+		}))
+		server.EnableHTTP2 = true
+		server.StartTLS()
+
+		resource := func(ctx context.Context) (map[string]string, error) { return nil, nil }
+		otlp := NewOTLP(server.URL, true, time.Second, nil, resource)
+		otlp.doer.client = server.Client()
+
+		err := otlp.Push(context.Background(), testBatches())
+		if (err != nil) != test.err {
+			t.Fatalf("unexpected error %#v for status %v", err, test.status)
+		}
+		var permanent *permanentError
+		if got := errors.As(err, &permanent); got != test.permanent {
+			t.Fatalf("got permanent %v, want %v for status %v", got, test.permanent, test.status)
+		}
+		if len(got) != 4 {
+			t.Fatalf("got %v, want 4 data points", got)
+		}
+
+		server.Close()
+	}
+}
@@ -0,0 +1,136 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Batch is the metric families gathered at once.
+type Batch struct {
+	Time     time.Time
+	Families []*dto.MetricFamily
+}
+
+// Target receives gathered batches in the order they were gathered.
+type Target interface {
+	Push(ctx context.Context, batches []Batch) error
+}
+
+// permanentError is an error that retrying the same push cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Pusher periodically gathers metrics and pushes them to a target. Batches
+// that could not be pushed are buffered and pushed along with later ones.
+type Pusher struct {
+	gatherer   prometheus.Gatherer
+	target     Target
+	retries    int
+	backoff    time.Duration
+	bufferSize int
+	now        func() time.Time
+
+	buffer []Batch
+}
+
+// NewPusher returns a pusher that retries a failed push up to retries times,
+// doubling backoff between attempts, and buffers at most bufferSize batches.
+func NewPusher(gatherer prometheus.Gatherer, target Target, retries int, backoff time.Duration, bufferSize int) *Pusher {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	return &Pusher{
+		gatherer:   gatherer,
+		target:     target,
+		retries:    retries,
+		backoff:    backoff,
+		bufferSize: bufferSize,
+		now:        time.Now,
+	}
+}
+
+// Run pushes every interval until ctx is done.
+func (pusher *Pusher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := pusher.Push(ctx); err != nil {
+			log.Printf("push: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Push gathers metrics once and pushes them along with the buffered ones.
+// Run calls Push, so it is not safe to call both concurrently.
+func (pusher *Pusher) Push(ctx context.Context) error {
+	// Like the /metrics handler, whatever could be gathered is pushed.
+	families, err := pusher.gatherer.Gather()
+	if err != nil {
+		log.Printf("push: %v", err)
+	}
+
+	pusher.buffer = append(pusher.buffer, Batch{Time: pusher.now(), Families: families})
+	if dropped := len(pusher.buffer) - pusher.bufferSize; dropped > 0 {
+		log.Printf("push: buffer full, dropping %d oldest batches", dropped)
+		pusher.buffer = pusher.buffer[dropped:]
+	}
+
+	backoff := pusher.backoff
+	for attempt := 0; ; attempt++ {
+		err = pusher.target.Push(ctx, pusher.buffer)
+
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) {
+			pusher.buffer = nil
+			return err
+		}
+		if attempt >= pusher.retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// headerDoer adds headers to every request.
+type headerDoer struct {
+	client  *http.Client
+	headers http.Header
+}
+
+func (doer *headerDoer) Do(req *http.Request) (*http.Response, error) {
+	for name, values := range doer.headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	return doer.client.Do(req)
+}
@@ -0,0 +1,103 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fakeTarget fails the first failures pushes with err and records the
+// number of batches of every push.
+type fakeTarget struct {
+	failures int
+	err      error
+	pushes   []int
+}
+
+func (target *fakeTarget) Push(ctx context.Context, batches []Batch) error {
+	target.pushes = append(target.pushes, len(batches))
+	if target.failures > 0 {
+		target.failures--
+		return target.err
+	}
+	return nil
+}
+
+func newTestPusher(target Target, retries, bufferSize int) *Pusher {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "test"}))
+	return NewPusher(registry, target, retries, time.Millisecond, bufferSize)
+}
+
+func TestPusherPushRetries(t *testing.T) {
+	target := &fakeTarget{failures: 2, err: errors.New("unavailable")}
+	pusher := newTestPusher(target, 2, 10)
+
+	if err := pusher.Push(context.Background()); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := len(target.pushes); got != 3 {
+		t.Fatalf("got %v pushes, want 3", got)
+	}
+	if got := len(pusher.buffer); got != 0 {
+		t.Fatalf("got %v buffered batches, want 0", got)
+	}
+}
+
+func TestPusherPushBuffers(t *testing.T) {
+	target := &fakeTarget{failures: 4, err: errors.New("unavailable")}
+	pusher := newTestPusher(target, 0, 3)
+
+	for i := 0; i < 5; i++ {
+		err := pusher.Push(context.Background())
+		if i < 4 && err != target.err {
+			t.Fatalf("unexpected error %#v", err)
+		}
+		if i == 4 && err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+
+	want := "[1 2 3 3 3]"
+	if got := fmt.Sprint(target.pushes); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := len(pusher.buffer); got != 0 {
+		t.Fatalf("got %v buffered batches, want 0", got)
+	}
+}
+
+func TestPusherPushPermanentError(t *testing.T) {
+	err := &permanentError{errors.New("bad request")}
+	target := &fakeTarget{failures: 1, err: err}
+	pusher := newTestPusher(target, 3, 10)
+
+	if got := pusher.Push(context.Background()); got != err {
+		t.Fatalf("unexpected error %#v", got)
+	}
+	if got := len(target.pushes); got != 1 {
+		t.Fatalf("got %v pushes, want 1", got)
+	}
+	if got := len(pusher.buffer); got != 0 {
+		t.Fatalf("got %v buffered batches, want 0", got)
+	}
+}
+
+func TestPusherPushCanceled(t *testing.T) {
+	target := &fakeTarget{failures: 1, err: errors.New("unavailable")}
+	pusher := newTestPusher(target, 3, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := pusher.Push(ctx); err != context.Canceled {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := len(pusher.buffer); got != 1 {
+		t.Fatalf("got %v buffered batches, want 1", got)
+	}
+}
@@ -0,0 +1,45 @@
+package push
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Pushgateway replaces the metrics of a group on a Pushgateway. As the
+// Pushgateway keeps no history, only the latest batch is pushed.
+type Pushgateway struct {
+	url      string
+	job      string
+	grouping map[string]string
+	doer     *headerDoer
+}
+
+// NewPushgateway returns a target pushing to the Pushgateway at url under
+// the job and grouping key.
+func NewPushgateway(url, job string, grouping map[string]string, client *http.Client, headers http.Header) *Pushgateway {
+	return &Pushgateway{
+		url:      url,
+		job:      job,
+		grouping: grouping,
+		doer:     &headerDoer{client: client, headers: headers},
+	}
+}
+
+func (gateway *Pushgateway) Push(ctx context.Context, batches []Batch) error {
+	latest := batches[len(batches)-1]
+
+	pusher := push.New(gateway.url, gateway.job).
+		Client(gateway.doer).
+		Gatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return latest.Families, nil
+		}))
+	for name, value := range gateway.grouping {
+		pusher.Grouping(name, value)
+	}
+
+	return pusher.PushContext(ctx)
+}
@@ -0,0 +1,76 @@
+package push
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+func gaugeFamily(name string, value float64) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name: proto.String(name),
+		Help: proto.String(name),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{
+			{Gauge: &dto.Gauge{Value: proto.Float64(value)}},
+		},
+	}
+}
+
+func TestPushgatewayPush(t *testing.T) {
+	var method, path, header, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("could not read a request: %#v", err)
+		}
+		method, path, header, body = r.Method, r.URL.Path, r.Header.Get("X-Api-Key"), string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	headers := http.Header{"X-Api-Key": []string{"secret"}}
+	gateway := NewPushgateway(server.URL, "ethereum", map[string]string{"node": "geth-1"}, server.Client(), headers)
+
+	batches := []Batch{
+		{Time: time.Unix(1, 0), Families: []*dto.MetricFamily{gaugeFamily("old", 1)}},
+		{Time: time.Unix(2, 0), Families: []*dto.MetricFamily{gaugeFamily("latest", 2)}},
+	}
+	if err := gateway.Push(context.Background(), batches); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Fatalf("got %v, want PUT", method)
+	}
+	if want := "/metrics/job/ethereum/node/geth-1"; path != want {
+		t.Fatalf("got %v, want %v", path, want)
+	}
+	if header != "secret" {
+		t.Fatalf("got %v, want secret", header)
+	}
+	if !strings.Contains(body, "latest") || strings.Contains(body, "old") {
+		t.Fatalf("got %q, want only the latest batch", body)
+	}
+}
+
+func TestPushgatewayPushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	gateway := NewPushgateway(server.URL, "ethereum", nil, server.Client(), nil)
+
+	batches := []Batch{{Families: []*dto.MetricFamily{gaugeFamily("latest", 2)}}}
+	if err := gateway.Push(context.Background(), batches); err == nil {
+		t.Fatal("expected an error")
+	}
+}
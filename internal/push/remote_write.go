@@ -0,0 +1,253 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxWriteRequestBytes bounds the uncompressed size of a single request, so
+// that a large buffer is not rejected by receivers as a whole.
+const maxWriteRequestBytes = 1 << 20
+
+// RemoteWrite sends batches to a Prometheus remote_write endpoint, such as
+// Prometheus itself, Cortex, Mimir or Thanos Receive.
+type RemoteWrite struct {
+	url      string
+	doer     *headerDoer
+	maxBytes int
+}
+
+// NewRemoteWrite returns a target sending to the remote_write endpoint at url.
+func NewRemoteWrite(url string, client *http.Client, headers http.Header) *RemoteWrite {
+	return &RemoteWrite{
+		url:      url,
+		doer:     &headerDoer{client: client, headers: headers},
+		maxBytes: maxWriteRequestBytes,
+	}
+}
+
+// Push sends batches in requests of at most maxBytes. A request rejected
+// permanently is dropped without holding up the others, and the error is
+// returned once they are sent. Other errors stop the push, so that it is
+// retried as a whole. Receivers ignore samples they already have.
+func (remote *RemoteWrite) Push(ctx context.Context, batches []Batch) error {
+	var rejected error
+	for _, request := range encodeWriteRequests(timeSeries(batches), remote.maxBytes) {
+		err := remote.write(ctx, request)
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			rejected = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return rejected
+}
+
+func (remote *RemoteWrite) write(ctx context.Context, request []byte) error {
+	body := snappy.Encode(nil, request)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, remote.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := remote.doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("unexpected status code %d while writing to %s: %s", resp.StatusCode, remote.url, bytes.TrimSpace(message))
+
+	// Client errors other than rate limiting are not retried, as the same
+	// request would be rejected again.
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+type series struct {
+	labels  []label
+	samples []sample
+}
+
+// timeSeries flattens batches into series in order of appearance, each with
+// its samples in the order they were gathered.
+func timeSeries(batches []Batch) []*series {
+	var result []*series
+	index := make(map[string]*series)
+
+	add := func(name string, labels []*dto.LabelPair, extra *label, value float64, timestamp int64) {
+		ls := []label{{name: "__name__", value: name}}
+		for _, pair := range labels {
+			ls = append(ls, label{name: pair.GetName(), value: pair.GetValue()})
+		}
+		if extra != nil {
+			ls = append(ls, *extra)
+		}
+		sort.Slice(ls, func(i, j int) bool { return ls[i].name < ls[j].name })
+
+		var key strings.Builder
+		for _, l := range ls {
+			key.WriteString(l.name + "\xff" + l.value + "\xff")
+		}
+
+		s, ok := index[key.String()]
+		if !ok {
+			s = &series{labels: ls}
+			index[key.String()] = s
+			result = append(result, s)
+		}
+		s.samples = append(s.samples, sample{value: value, timestamp: timestamp})
+	}
+
+	for _, batch := range batches {
+		for _, family := range batch.Families {
+			name := family.GetName()
+			for _, metric := range family.Metric {
+				timestamp := batch.Time.UnixMilli()
+				if metric.TimestampMs != nil {
+					timestamp = metric.GetTimestampMs()
+				}
+
+				switch {
+				case metric.Counter != nil:
+					add(name, metric.Label, nil, metric.Counter.GetValue(), timestamp)
+				case metric.Gauge != nil:
+					add(name, metric.Label, nil, metric.Gauge.GetValue(), timestamp)
+				case metric.Untyped != nil:
+					add(name, metric.Label, nil, metric.Untyped.GetValue(), timestamp)
+				case metric.Summary != nil:
+					for _, quantile := range metric.Summary.Quantile {
+						extra := &label{name: "quantile", value: formatFloat(quantile.GetQuantile())}
+						add(name, metric.Label, extra, quantile.GetValue(), timestamp)
+					}
+					add(name+"_sum", metric.Label, nil, metric.Summary.GetSampleSum(), timestamp)
+					add(name+"_count", metric.Label, nil, float64(metric.Summary.GetSampleCount()), timestamp)
+				case metric.Histogram != nil:
+					infSeen := false
+					for _, bucket := range metric.Histogram.Bucket {
+						infSeen = infSeen || math.IsInf(bucket.GetUpperBound(), 1)
+						extra := &label{name: "le", value: formatFloat(bucket.GetUpperBound())}
+						add(name+"_bucket", metric.Label, extra, float64(bucket.GetCumulativeCount()), timestamp)
+					}
+					count := float64(metric.Histogram.GetSampleCount())
+					if !infSeen {
+						add(name+"_bucket", metric.Label, &label{name: "le", value: "+Inf"}, count, timestamp)
+					}
+					add(name+"_sum", metric.Label, nil, metric.Histogram.GetSampleSum(), timestamp)
+					add(name+"_count", metric.Label, nil, count, timestamp)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// formatFloat formats label values like the text exposition format.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequests encodes series into prometheus.WriteRequest protobuf
+// messages of at most maxBytes each, unless a single sample exceeds it:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+//
+// Series that do not fit are split, keeping their samples in order.
+func encodeWriteRequests(series []*series, maxBytes int) [][]byte {
+	var requests [][]byte
+	var request []byte
+
+	for _, s := range series {
+		for samples := s.samples; len(samples) > 0; {
+			n := len(samples)
+			ts := encodeTimeSeries(s.labels, samples[:n])
+			for n > 1 && timeSeriesSize(ts) > maxBytes {
+				n /= 2
+				ts = encodeTimeSeries(s.labels, samples[:n])
+			}
+			samples = samples[n:]
+
+			if len(request) > 0 && len(request)+timeSeriesSize(ts) > maxBytes {
+				requests = append(requests, request)
+				request = nil
+			}
+			request = protowire.AppendTag(request, 1, protowire.BytesType)
+			request = protowire.AppendBytes(request, ts)
+		}
+	}
+
+	if len(request) > 0 {
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// timeSeriesSize returns the size of an encoded TimeSeries in a WriteRequest.
+func timeSeriesSize(ts []byte) int {
+	return protowire.SizeTag(1) + protowire.SizeBytes(len(ts))
+}
+
+func encodeTimeSeries(labels []label, samples []sample) []byte {
+	var ts []byte
+	for _, l := range labels {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, l.name)
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, l.value)
+
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, b)
+	}
+	for _, smp := range samples {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(smp.value))
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(smp.timestamp))
+
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, b)
+	}
+	return ts
+}
@@ -0,0 +1,178 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/proto"
+)
+
+// decodeWriteRequest decodes a snappy compressed WriteRequest into lines
+// such as `name{label="value"} 1@1000` in order of appearance.
+func decodeWriteRequest(t *testing.T, r *http.Request) []string {
+	compressed, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("could not read a request: %#v", err)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		t.Fatalf("could not decompress a request: %#v", err)
+	}
+
+	var request prompb.WriteRequest
+	if err := request.Unmarshal(data); err != nil {
+		t.Fatalf("could not decode a request: %#v", err)
+	}
+
+	var lines []string
+	for _, ts := range request.Timeseries {
+		var name string
+		var labels, samples []string
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				name = l.Value
+			} else {
+				labels = append(labels, fmt.Sprintf("%s=%q", l.Name, l.Value))
+			}
+		}
+		for _, s := range ts.Samples {
+			samples = append(samples, fmt.Sprintf("%v@%v", s.Value, s.Timestamp))
+		}
+		lines = append(lines, fmt.Sprintf("%s{%s} %s", name, strings.Join(labels, ","), strings.Join(samples, " ")))
+	}
+	return lines
+}
+
+func TestRemoteWritePush(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, want := range map[string]string{
+			"Content-Encoding":                  "snappy",
+			"Content-Type":                      "application/x-protobuf",
+			"X-Prometheus-Remote-Write-Version": "0.1.0",
+			"X-Scope-Orgid":                     "tenant",
+		} {
+			if got := r.Header.Get(name); got != want {
+				t.Errorf("got %v, want %v for %v", got, want, name)
+			}
+		}
+
+		got = decodeWriteRequest(t, r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	headers := http.Header{"X-Scope-Orgid": []string{"tenant"}}
+	remote := NewRemoteWrite(server.URL, server.Client(), headers)
+
+	histogram := &dto.MetricFamily{
+		Name: proto.String("latency"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{
+			Label: []*dto.LabelPair{{Name: proto.String("method"), Value: proto.String("trace_block")}},
+			Histogram: &dto.Histogram{
+				SampleCount: proto.Uint64(3),
+				SampleSum:   proto.Float64(4.5),
+				Bucket: []*dto.Bucket{
+					{UpperBound: proto.Float64(0.5), CumulativeCount: proto.Uint64(1)},
+					{UpperBound: proto.Float64(2), CumulativeCount: proto.Uint64(2)},
+				},
+			},
+		}},
+	}
+	batches := []Batch{
+		{Time: time.UnixMilli(1000), Families: []*dto.MetricFamily{gaugeFamily("eth_block_number", 100)}},
+		{Time: time.UnixMilli(2000), Families: []*dto.MetricFamily{gaugeFamily("eth_block_number", 101), histogram}},
+	}
+	if err := remote.Push(context.Background(), batches); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := []string{
+		`eth_block_number{} 100@1000 101@2000`,
+		`latency_bucket{le="0.5",method="trace_block"} 1@2000`,
+		`latency_bucket{le="2",method="trace_block"} 2@2000`,
+		`latency_bucket{le="+Inf",method="trace_block"} 3@2000`,
+		`latency_sum{method="trace_block"} 4.5@2000`,
+		`latency_count{method="trace_block"} 3@2000`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestRemoteWritePushError(t *testing.T) {
+	for _, test := range []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+		}))
+
+		remote := NewRemoteWrite(server.URL, server.Client(), nil)
+		err := remote.Push(context.Background(), []Batch{{Families: []*dto.MetricFamily{gaugeFamily("up", 1)}}})
+		if err == nil {
+			t.Fatalf("expected an error for status %v", test.status)
+		}
+
+		var permanent *permanentError
+		if got := errors.As(err, &permanent); got != test.permanent {
+			t.Fatalf("got permanent %v, want %v for status %v", got, test.permanent, test.status)
+		}
+
+		server.Close()
+	}
+}
+
+func TestRemoteWritePushSplits(t *testing.T) {
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, decodeWriteRequest(t, r))
+
+		// The first request is rejected, which does not hold up the others.
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	remote := NewRemoteWrite(server.URL, server.Client(), nil)
+	// Encoded series take 30 bytes with one sample, 43 with two and 56 with
+	// three, so that each request holds one or two samples.
+	remote.maxBytes = 50
+
+	var batches []Batch
+	for i := 0; i < 3; i++ {
+		batches = append(batches, Batch{
+			Time:     time.UnixMilli(int64(i)),
+			Families: []*dto.MetricFamily{gaugeFamily("a", float64(i)), gaugeFamily("b", float64(i))},
+		})
+	}
+
+	var permanent *permanentError
+	if err := remote.Push(context.Background(), batches); !errors.As(err, &permanent) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	want := "[[a{} 0@0] [a{} 1@1 2@2] [b{} 0@0] [b{} 1@1 2@2]]"
+	if got := fmt.Sprint(requests); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwtRefreshInterval is how long a signed token is reused. The Engine API
+// rejects tokens issued more than 60 seconds ago.
+const jwtRefreshInterval = 30 * time.Second
+
+type bearerAuth struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+func newBearerAuth(path string) (rpc.HTTPAuth, error) {
+	auth := &bearerAuth{path: path}
+	if _, err := auth.load(); err != nil {
+		return nil, err
+	}
+
+	return auth.apply, nil
+}
+
+func (auth *bearerAuth) load() (string, error) {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+
+	info, err := os.Stat(auth.path)
+	if err != nil {
+		return "", err
+	}
+	if info.ModTime().Equal(auth.modTime) {
+		return auth.token, nil
+	}
+
+	data, err := os.ReadFile(auth.path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.New("empty bearer token in " + auth.path)
+	}
+
+	auth.token = token
+	auth.modTime = info.ModTime()
+
+	return auth.token, nil
+}
+
+func (auth *bearerAuth) apply(h http.Header) error {
+	token, err := auth.load()
+	if err != nil {
+		return err
+	}
+
+	h.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+type jwtAuth struct {
+	secret []byte
+	now    func() time.Time
+
+	mu       sync.Mutex
+	issuedAt time.Time
+	token    string
+}
+
+func newJWTAuth(path string) (rpc.HTTPAuth, error) {
+	secret, err := readJWTSecret(path)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &jwtAuth{secret: secret, now: time.Now}
+	return auth.apply, nil
+}
+
+func readJWTSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, "0x") {
+		text = "0x" + text
+	}
+
+	secret, err := hexutil.Decode(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT secret in %s: %w", path, err)
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid JWT secret in %s: want 32 bytes, got %d", path, len(secret))
+	}
+
+	return secret, nil
+}
+
+func (auth *jwtAuth) apply(h http.Header) error {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+
+	now := auth.now()
+	if auth.token == "" || now.Sub(auth.issuedAt) >= jwtRefreshInterval {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iat": &jwt.NumericDate{Time: now},
+		})
+
+		signed, err := token.SignedString(auth.secret)
+		if err != nil {
+			return fmt.Errorf("could not sign JWT: %w", err)
+		}
+
+		auth.token = signed
+		auth.issuedAt = now
+	}
+
+	h.Set("Authorization", "Bearer "+auth.token)
+	return nil
+}
@@ -0,0 +1,145 @@
+package upstream
+
+import (
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BuildFunc creates the collectors for a single endpoint. Chain collectors
+// report values that are the same on every healthy node while node
+// collectors report the state of the node itself.
+type BuildFunc func(rpc *rpc.Client) (chain, node []prometheus.Collector)
+
+// Collector delegates each scrape to the collectors of the active endpoint
+// of a Pool. Unchecked collectors, which describe no metrics, are collected
+// by the collector returned by Unchecked instead.
+type Collector struct {
+	pool       *Pool
+	collectors []collectorList
+	unchecked  []collectorList
+	activeDesc *prometheus.Desc
+}
+
+// NewCollector builds collectors for every endpoint of pool. When the pool
+// has more than one endpoint, metrics of node collectors are labeled with
+// the endpoint name.
+func NewCollector(pool *Pool, build BuildFunc) *Collector {
+	collector := &Collector{
+		pool: pool,
+		activeDesc: prometheus.NewDesc(
+			"ethereum_exporter_active_upstream",
+			"whether the upstream served the last scrape",
+			[]string{"upstream"},
+			nil,
+		),
+	}
+
+	for _, endpoint := range pool.Endpoints() {
+		chain, node := build(endpoint.Client)
+
+		collectors := collectorList(chain)
+
+		var registerer prometheus.Registerer = &collectors
+		if len(pool.Endpoints()) > 1 {
+			registerer = prometheus.WrapRegistererWith(prometheus.Labels{"upstream": endpoint.Name}, registerer)
+		}
+		registerer.MustRegister(node...)
+
+		var checked, unchecked collectorList
+		for _, c := range collectors {
+			if isUnchecked(c) {
+				unchecked = append(unchecked, c)
+			} else {
+				checked = append(checked, c)
+			}
+		}
+		collector.collectors = append(collector.collectors, checked)
+		collector.unchecked = append(collector.unchecked, unchecked)
+	}
+
+	return collector
+}
+
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.activeDesc
+	for _, collectors := range collector.collectors {
+		for _, c := range collectors {
+			c.Describe(ch)
+		}
+	}
+}
+
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	active := collector.pool.Active()
+
+	for i, endpoint := range collector.pool.Endpoints() {
+		value := 0.0
+		if endpoint == active {
+			value = 1
+			for _, c := range collector.collectors[i] {
+				c.Collect(ch)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(collector.activeDesc, prometheus.GaugeValue, value, endpoint.Name)
+	}
+}
+
+// Unchecked returns an unchecked collector delegating each scrape to the
+// unchecked collectors of the active endpoint. It has to be registered
+// along with the Collector.
+func (collector *Collector) Unchecked() prometheus.Collector {
+	return uncheckedCollector{collector}
+}
+
+type uncheckedCollector struct {
+	collector *Collector
+}
+
+func (collector uncheckedCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (collector uncheckedCollector) Collect(ch chan<- prometheus.Metric) {
+	pool := collector.collector.pool
+	active := pool.Active()
+
+	for i, endpoint := range pool.Endpoints() {
+		if endpoint == active {
+			for _, c := range collector.collector.unchecked[i] {
+				c.Collect(ch)
+			}
+		}
+	}
+}
+
+// isUnchecked reports whether c describes no metrics, which the registry
+// treats as an unchecked collector.
+func isUnchecked(c prometheus.Collector) bool {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(ch)
+		close(ch)
+	}()
+
+	unchecked := true
+	for range ch {
+		unchecked = false
+	}
+	return unchecked
+}
+
+// collectorList is a prometheus.Registerer that only keeps track of the
+// collectors registered with it. Combined with prometheus.WrapRegistererWith
+// it labels collectors without registering them anywhere.
+type collectorList []prometheus.Collector
+
+func (list *collectorList) Register(c prometheus.Collector) error {
+	*list = append(*list, c)
+	return nil
+}
+
+func (list *collectorList) MustRegister(cs ...prometheus.Collector) {
+	*list = append(*list, cs...)
+}
+
+func (list *collectorList) Unregister(prometheus.Collector) bool {
+	return false
+}
@@ -0,0 +1,126 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Target is a JSON-RPC URL and the name identifying it in metrics, which
+// keeps credentials embedded in the URL out of labels.
+type Target struct {
+	Name string
+	URL  string
+}
+
+// Endpoint is a single JSON-RPC endpoint of a Pool.
+type Endpoint struct {
+	Name   string
+	URL    string
+	Client *rpc.Client
+
+	healthy atomic.Bool
+}
+
+// Healthy reports whether the endpoint passed its latest health check.
+func (e *Endpoint) Healthy() bool {
+	return e.healthy.Load()
+}
+
+// Pool is an ordered list of JSON-RPC endpoints. The first healthy endpoint
+// is the active one.
+type Pool struct {
+	endpoints []*Endpoint
+	timeout   time.Duration
+}
+
+// NewPool dials every target in order of preference. Target names must be
+// unique. Endpoints are considered healthy until the first health check says
+// otherwise.
+func NewPool(targets []Target, opts *Options, timeout time.Duration) (*Pool, error) {
+	pool := &Pool{timeout: timeout}
+
+	names := make(map[string]bool)
+	for _, target := range targets {
+		if names[target.Name] {
+			pool.Close()
+			return nil, fmt.Errorf("duplicate upstream name %q", target.Name)
+		}
+		names[target.Name] = true
+
+		client, err := Dial(target.URL, opts)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+
+		endpoint := &Endpoint{Name: target.Name, URL: target.URL, Client: client}
+		endpoint.healthy.Store(true)
+		pool.endpoints = append(pool.endpoints, endpoint)
+	}
+
+	return pool, nil
+}
+
+// Endpoints returns all endpoints in order of preference.
+func (pool *Pool) Endpoints() []*Endpoint {
+	return pool.endpoints
+}
+
+// Active returns the first healthy endpoint. If none is healthy the first
+// endpoint is returned so that its errors are surfaced.
+func (pool *Pool) Active() *Endpoint {
+	for _, endpoint := range pool.endpoints {
+		if endpoint.Healthy() {
+			return endpoint
+		}
+	}
+	return pool.endpoints[0]
+}
+
+// Check concurrently health checks every endpoint.
+func (pool *Pool) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, endpoint := range pool.endpoints {
+		wg.Add(1)
+		go func(endpoint *Endpoint) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, pool.timeout)
+			defer cancel()
+
+			var result hexutil.Uint64
+			err := endpoint.Client.CallContext(ctx, &result, "eth_blockNumber")
+			endpoint.healthy.Store(err == nil)
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// Run health checks every endpoint each interval until ctx is done.
+func (pool *Pool) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pool.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes every endpoint connection.
+func (pool *Pool) Close() {
+	for _, endpoint := range pool.endpoints {
+		endpoint.Client.Close()
+	}
+}
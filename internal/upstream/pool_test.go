@@ -0,0 +1,161 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/31z4/ethereum-prometheus-exporter/internal/collector"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newNode(t *testing.T, healthy *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": "0x10"}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func TestPoolFailover(t *testing.T) {
+	var primaryHealthy, secondaryHealthy atomic.Bool
+	primaryHealthy.Store(true)
+	secondaryHealthy.Store(true)
+
+	primary := newNode(t, &primaryHealthy)
+	defer primary.Close()
+	secondary := newNode(t, &secondaryHealthy)
+	defer secondary.Close()
+
+	pool, err := NewPool([]Target{{Name: "primary", URL: primary.URL}, {Name: "secondary", URL: secondary.URL}}, nil, time.Second)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	defer pool.Close()
+
+	pool.Check(context.Background())
+	if got := pool.Active().URL; got != primary.URL {
+		t.Fatalf("got %v, want %v", got, primary.URL)
+	}
+
+	primaryHealthy.Store(false)
+	pool.Check(context.Background())
+	if got := pool.Active().URL; got != secondary.URL {
+		t.Fatalf("got %v, want %v", got, secondary.URL)
+	}
+
+	secondaryHealthy.Store(false)
+	pool.Check(context.Background())
+	if got := pool.Active().URL; got != primary.URL {
+		t.Fatalf("got %v, want %v", got, primary.URL)
+	}
+
+	primaryHealthy.Store(true)
+	pool.Check(context.Background())
+	if got := pool.Active().URL; got != primary.URL {
+		t.Fatalf("got %v, want %v", got, primary.URL)
+	}
+}
+
+func TestPoolDuplicateName(t *testing.T) {
+	if _, err := NewPool([]Target{{Name: "node", URL: "http://a"}, {Name: "node", URL: "http://b"}}, nil, time.Second); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestCollectorActiveUpstream(t *testing.T) {
+	var primaryHealthy, secondaryHealthy atomic.Bool
+	secondaryHealthy.Store(true)
+
+	primary := newNode(t, &primaryHealthy)
+	defer primary.Close()
+	secondary := newNode(t, &secondaryHealthy)
+	defer secondary.Close()
+
+	pool, err := NewPool([]Target{{Name: "primary", URL: primary.URL}, {Name: "secondary", URL: secondary.URL}}, nil, time.Second)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	defer pool.Close()
+	pool.Check(context.Background())
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewCollector(pool, func(rpc *rpc.Client) (chain, node []prometheus.Collector) {
+		return []prometheus.Collector{collector.NewEthBlockNumber(rpc)},
+			[]prometheus.Collector{collector.NewNetPeerCount(rpc)}
+	}))
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			name := family.GetName()
+			for _, label := range metric.Label {
+				name += "," + label.GetName() + "=" + label.GetValue()
+			}
+			values[name] = metric.Gauge.GetValue()
+		}
+	}
+
+	want := map[string]float64{
+		"eth_block_number": 16,
+		"ethereum_exporter_active_upstream,upstream=primary":   0,
+		"ethereum_exporter_active_upstream,upstream=secondary": 1,
+		"net_peers,upstream=secondary":                         16,
+	}
+	if len(values) != len(want) {
+		t.Fatalf("got %v, want %v", values, want)
+	}
+	for name, value := range want {
+		if got, ok := values[name]; !ok || got != value {
+			t.Fatalf("got %v, want %v", values, want)
+		}
+	}
+}
+
+func TestCollectorSingleUpstreamUnlabeled(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	node := newNode(t, &healthy)
+	defer node.Close()
+
+	pool, err := NewPool([]Target{{Name: "node", URL: node.URL}}, nil, time.Second)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	defer pool.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewCollector(pool, func(rpc *rpc.Client) (chain, node []prometheus.Collector) {
+		return nil, []prometheus.Collector{collector.NewNetPeerCount(rpc)}
+	}))
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "net_peers" {
+			continue
+		}
+		if got := len(family.Metric[0].Label); got > 0 {
+			t.Fatalf("expected 0 labels, got %d", got)
+		}
+		return
+	}
+	t.Fatalf("net_peers not collected")
+}
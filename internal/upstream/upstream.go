@@ -0,0 +1,134 @@
+package upstream
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// Options configures how the exporter connects and authenticates to an
+// Ethereum JSON-RPC endpoint.
+type Options struct {
+	// Headers are added to every HTTP request and WebSocket handshake.
+	Headers http.Header
+
+	// BearerTokenFile is a file holding a static bearer token. The file is
+	// re-read whenever it changes so that rotated tokens are picked up.
+	BearerTokenFile string
+
+	// JWTSecretFile is a file holding a hex encoded 32 byte secret used to
+	// sign Engine API style HS256 tokens.
+	JWTSecretFile string
+
+	// TLSCertFile and TLSKeyFile hold a client certificate for mutual TLS.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSCAFile holds PEM encoded certificates used to verify the endpoint.
+	TLSCAFile string
+
+	// TLSInsecureSkipVerify disables verification of the endpoint certificate.
+	TLSInsecureSkipVerify bool
+}
+
+// Dial connects to the JSON-RPC endpoint at url using opts.
+func Dial(url string, opts *Options) (*rpc.Client, error) {
+	clientOpts, err := opts.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return rpc.DialOptions(context.Background(), url, clientOpts...)
+}
+
+func (opts *Options) clientOptions() ([]rpc.ClientOption, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	var result []rpc.ClientOption
+
+	if len(opts.Headers) > 0 {
+		result = append(result, rpc.WithHeaders(opts.Headers))
+	}
+
+	if opts.BearerTokenFile != "" && opts.JWTSecretFile != "" {
+		return nil, errors.New("bearer token and JWT secret are mutually exclusive")
+	}
+
+	if opts.BearerTokenFile != "" {
+		auth, err := newBearerAuth(opts.BearerTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rpc.WithHTTPAuth(auth))
+	}
+
+	if opts.JWTSecretFile != "" {
+		auth, err := newJWTAuth(opts.JWTSecretFile)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rpc.WithHTTPAuth(auth))
+	}
+
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+
+		result = append(result,
+			rpc.WithHTTPClient(&http.Client{Transport: transport}),
+			rpc.WithWebsocketDialer(websocket.Dialer{
+				Proxy:            http.ProxyFromEnvironment,
+				HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+				TLSClientConfig:  tlsConfig,
+			}),
+		)
+	}
+
+	return result, nil
+}
+
+func (opts *Options) tlsConfig() (*tls.Config, error) {
+	if opts.TLSCertFile == "" && opts.TLSKeyFile == "" && opts.TLSCAFile == "" && !opts.TLSInsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: opts.TLSInsecureSkipVerify,
+	}
+
+	if opts.TLSCertFile != "" || opts.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.TLSCertFile, opts.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if opts.TLSCAFile != "" {
+		pem, err := os.ReadFile(opts.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + opts.TLSCAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
@@ -0,0 +1,196 @@
+package upstream
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "0x7365637265747365637265747365637265747365637265747365637265747365"
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("could not write %s: %#v", name, err)
+	}
+	return path
+}
+
+func newServer(t *testing.T, requests chan<- *http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		_, err := w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": "0x1"}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+}
+
+func call(t *testing.T, url string, opts *Options) {
+	rpc, err := Dial(url, opts)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	defer rpc.Close()
+
+	var result hexutil.Uint64
+	if err := rpc.Call(&result, "eth_blockNumber"); err != nil {
+		t.Fatalf("rpc call error: %#v", err)
+	}
+}
+
+func TestDialHeaders(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := newServer(t, requests)
+	defer server.Close()
+
+	headers := make(http.Header)
+	headers.Set("X-Api-Key", "secret")
+
+	call(t, server.URL, &Options{Headers: headers})
+
+	r := <-requests
+	if got := r.Header.Get("X-Api-Key"); got != "secret" {
+		t.Fatalf("got %q, want secret", got)
+	}
+}
+
+func TestDialBearerTokenFile(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := newServer(t, requests)
+	defer server.Close()
+
+	path := writeFile(t, "token", "first\n")
+	opts := &Options{BearerTokenFile: path}
+
+	call(t, server.URL, opts)
+	if got := (<-requests).Header.Get("Authorization"); got != "Bearer first" {
+		t.Fatalf("got %q, want Bearer first", got)
+	}
+
+	if err := os.WriteFile(path, []byte("second"), 0o600); err != nil {
+		t.Fatalf("could not rotate token: %#v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("could not touch token: %#v", err)
+	}
+
+	call(t, server.URL, opts)
+	if got := (<-requests).Header.Get("Authorization"); got != "Bearer second" {
+		t.Fatalf("got %q, want Bearer second", got)
+	}
+}
+
+func TestDialJWTSecretFile(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := newServer(t, requests)
+	defer server.Close()
+
+	call(t, server.URL, &Options{JWTSecretFile: writeFile(t, "jwt.hex", testSecret[2:])})
+
+	header := (<-requests).Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		t.Fatalf("unexpected authorization header %q", header)
+	}
+
+	secret := hexutil.MustDecode(testSecret)
+	token, err := jwt.Parse(strings.TrimPrefix(header, "Bearer "), func(*jwt.Token) (interface{}, error) {
+		return secret, nil
+	})
+	if err != nil {
+		t.Fatalf("invalid token: %#v", err)
+	}
+	if got := token.Method.Alg(); got != "HS256" {
+		t.Fatalf("got %v, want HS256", got)
+	}
+	if _, ok := token.Claims.(jwt.MapClaims)["iat"]; !ok {
+		t.Fatalf("expected iat claim, got %#v", token.Claims)
+	}
+}
+
+func TestJWTAuthRefresh(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := &jwtAuth{
+		secret: hexutil.MustDecode(testSecret),
+		now:    func() time.Time { return now },
+	}
+
+	h := make(http.Header)
+	if err := auth.apply(h); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	first := h.Get("Authorization")
+
+	now = now.Add(jwtRefreshInterval / 2)
+	if err := auth.apply(h); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := h.Get("Authorization"); got != first {
+		t.Fatalf("expected token to be reused")
+	}
+
+	now = now.Add(jwtRefreshInterval)
+	if err := auth.apply(h); err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if got := h.Get("Authorization"); got == first {
+		t.Fatalf("expected token to be refreshed")
+	}
+}
+
+func TestReadJWTSecretInvalid(t *testing.T) {
+	if _, err := readJWTSecret(writeFile(t, "jwt.hex", "0x1234")); err == nil {
+		t.Fatalf("expected error for short secret")
+	}
+	if _, err := readJWTSecret(writeFile(t, "jwt.hex", "test")); err == nil {
+		t.Fatalf("expected error for non hex secret")
+	}
+}
+
+func TestDialAuthMutuallyExclusive(t *testing.T) {
+	opts := &Options{
+		BearerTokenFile: writeFile(t, "token", "token"),
+		JWTSecretFile:   writeFile(t, "jwt.hex", testSecret),
+	}
+
+	if _, err := Dial("http://localhost", opts); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestDialTLSCAFile(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		_, err := w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": "0x1"}`))
+		if err != nil {
+			t.Fatalf("could not write a response: %#v", err)
+		}
+	}))
+	defer server.Close()
+
+	rpc, err := Dial(server.URL, nil)
+	if err != nil {
+		t.Fatalf("rpc connection error: %#v", err)
+	}
+	var result hexutil.Uint64
+	if err := rpc.Call(&result, "eth_blockNumber"); err == nil {
+		t.Fatalf("expected certificate error")
+	}
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	call(t, server.URL, &Options{TLSCAFile: writeFile(t, "ca.pem", string(ca))})
+
+	if r := <-requests; r.TLS == nil {
+		t.Fatalf("expected TLS request")
+	}
+}